@@ -0,0 +1,150 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Limits of the W3C Baggage specification.
+const (
+	MaxBaggageMembers     = 180
+	MaxBaggageMemberBytes = 4096
+	MaxBaggageBytes       = 8192
+)
+
+var (
+	// ErrInvalidBaggage is returned when a baggage key or value does not
+	// comply with the W3C Baggage specification.
+	ErrInvalidBaggage = errors.New("invalid baggage member")
+
+	// ErrBaggageTooLarge is returned when setting a baggage member would
+	// exceed the limits of the W3C Baggage specification.
+	ErrBaggageTooLarge = errors.New("baggage too large")
+)
+
+// SetBaggage returns a copy of `ctx` whose baggage holds the key and value.
+// The value is stored as is and percent-encoded when propagated. The original
+// context is returned along with an error when the member is invalid or would
+// make the baggage exceed the W3C limits, see `MaxBaggageMembers`,
+// `MaxBaggageMemberBytes` and `MaxBaggageBytes`.
+func SetBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	if !isBaggageKey(key) {
+		return ctx, fmt.Errorf("%w %q: key must be an RFC 7230 token", ErrInvalidBaggage, key)
+	}
+
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("%w %q: %w", ErrInvalidBaggage, key, err)
+	}
+	if n := len(member.String()); n > MaxBaggageMemberBytes {
+		return ctx, fmt.Errorf(
+			"%w: member %q is %d bytes, limit is %d", ErrBaggageTooLarge, key, n, MaxBaggageMemberBytes,
+		)
+	}
+
+	b, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("%w %q: %w", ErrInvalidBaggage, key, err)
+	}
+	if n := b.Len(); n > MaxBaggageMembers {
+		return ctx, fmt.Errorf("%w: %d members, limit is %d", ErrBaggageTooLarge, n, MaxBaggageMembers)
+	}
+	if n := len(b.String()); n > MaxBaggageBytes {
+		return ctx, fmt.Errorf("%w: %d bytes, limit is %d", ErrBaggageTooLarge, n, MaxBaggageBytes)
+	}
+
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// isBaggageKey reports whether the key is a token as defined by RFC 7230,
+// which the W3C Baggage specification requires for propagation.
+func isBaggageKey(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetBaggage returns the value of the baggage member identified by key, or an
+// empty string if there is none.
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// DeleteBaggage returns a copy of `ctx` whose baggage no longer holds the key.
+func DeleteBaggage(ctx context.Context, key string) context.Context {
+	return baggage.ContextWithBaggage(ctx, baggage.FromContext(ctx).DeleteMember(key))
+}
+
+// BaggageFromContext returns all the baggage members of `ctx` as key/value
+// pairs. Member properties are omitted.
+func BaggageFromContext(ctx context.Context) map[string]string {
+	members := baggage.FromContext(ctx).Members()
+
+	values := make(map[string]string, len(members))
+	for _, member := range members {
+		values[member.Key()] = member.Value()
+	}
+
+	return values
+}
+
+// BaggageSpanProcessor copies selected baggage members of the parent context
+// onto every span as attributes when it starts.
+type BaggageSpanProcessor struct {
+	keys []string
+}
+
+var _ sdktrace.SpanProcessor = (*BaggageSpanProcessor)(nil)
+
+// NewBaggageSpanProcessor returns a new `BaggageSpanProcessor` copying the
+// baggage members identified by keys. Attributes are named after the keys.
+func NewBaggageSpanProcessor(keys ...string) *BaggageSpanProcessor {
+	return &BaggageSpanProcessor{keys: keys}
+}
+
+// OnStart implements `sdktrace.SpanProcessor`.
+func (p *BaggageSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	b := baggage.FromContext(parent)
+	if b.Len() == 0 {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(p.keys))
+	for _, key := range p.keys {
+		if member := b.Member(key); len(member.Key()) > 0 {
+			attrs = append(attrs, attribute.String(key, member.Value()))
+		}
+	}
+
+	s.SetAttributes(attrs...)
+}
+
+// OnEnd implements `sdktrace.SpanProcessor`.
+func (p *BaggageSpanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+// Shutdown implements `sdktrace.SpanProcessor`.
+func (p *BaggageSpanProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush implements `sdktrace.SpanProcessor`.
+func (p *BaggageSpanProcessor) ForceFlush(context.Context) error {
+	return nil
+}
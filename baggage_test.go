@@ -0,0 +1,130 @@
+package trace_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestSetBaggage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sets and gets members", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := trace.SetBaggage(context.Background(), "tenant.id", "acme corp")
+		if err != nil {
+			t.Fatalf("SetBaggage failed: %v", err)
+		}
+		ctx, err = trace.SetBaggage(ctx, "user.id", "42")
+		if err != nil {
+			t.Fatalf("SetBaggage failed: %v", err)
+		}
+
+		if got := trace.GetBaggage(ctx, "tenant.id"); got != "acme corp" {
+			t.Errorf("expected tenant.id=%q, got %q", "acme corp", got)
+		}
+		if got := trace.GetBaggage(ctx, "missing"); got != "" {
+			t.Errorf("expected missing member to be empty, got %q", got)
+		}
+
+		members := trace.BaggageFromContext(ctx)
+		if len(members) != 2 || members["user.id"] != "42" {
+			t.Errorf("unexpected baggage members: %v", members)
+		}
+
+		ctx = trace.DeleteBaggage(ctx, "user.id")
+		if got := trace.GetBaggage(ctx, "user.id"); got != "" {
+			t.Errorf("expected deleted member to be empty, got %q", got)
+		}
+	})
+
+	t.Run("rejects invalid key", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		got, err := trace.SetBaggage(ctx, "invalid key", "value")
+		if !errors.Is(err, trace.ErrInvalidBaggage) {
+			t.Errorf("expected error %v, got %v", trace.ErrInvalidBaggage, err)
+		}
+		if got != ctx {
+			t.Error("expected original context to be returned")
+		}
+	})
+
+	t.Run("rejects too large member", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := trace.SetBaggage(context.Background(), "large", strings.Repeat("x", trace.MaxBaggageMemberBytes))
+		if !errors.Is(err, trace.ErrBaggageTooLarge) {
+			t.Errorf("expected error %v, got %v", trace.ErrBaggageTooLarge, err)
+		}
+	})
+
+	t.Run("rejects too large baggage", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		value := strings.Repeat("x", trace.MaxBaggageMemberBytes/2)
+
+		var err error
+		for i := 0; err == nil && i < 10; i++ {
+			ctx, err = trace.SetBaggage(ctx, fmt.Sprintf("key%d", i), value)
+		}
+		if !errors.Is(err, trace.ErrBaggageTooLarge) {
+			t.Errorf("expected error %v, got %v", trace.ErrBaggageTooLarge, err)
+		}
+	})
+
+	t.Run("rejects too many members", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		var err error
+		for i := 0; err == nil && i <= trace.MaxBaggageMembers; i++ {
+			ctx, err = trace.SetBaggage(ctx, fmt.Sprintf("k%d", i), "v")
+		}
+		if !errors.Is(err, trace.ErrBaggageTooLarge) {
+			t.Errorf("expected error %v, got %v", trace.ErrBaggageTooLarge, err)
+		}
+	})
+}
+
+func TestBaggageSpanProcessor(t *testing.T) {
+	t.Parallel()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(trace.NewBaggageSpanProcessor("tenant.id", "missing")),
+		sdktrace.WithSpanProcessor(sr),
+	)
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	ctx, err := trace.SetBaggage(context.Background(), "tenant.id", "acme")
+	if err != nil {
+		t.Fatalf("SetBaggage failed: %v", err)
+	}
+	ctx, err = trace.SetBaggage(ctx, "secret", "hidden")
+	if err != nil {
+		t.Fatalf("SetBaggage failed: %v", err)
+	}
+
+	_, span := tp.Tracer("test").Start(ctx, "test-span")
+	span.End()
+
+	attrs := sr.Ended()[0].Attributes()
+	if len(attrs) != 1 {
+		t.Fatalf("expected 1 attribute, got %d", len(attrs))
+	}
+	if attrs[0].Key != "tenant.id" || attrs[0].Value.AsString() != "acme" {
+		t.Errorf("expected attribute tenant.id=%q, got %s=%q", "acme", attrs[0].Key, attrs[0].Value.AsString())
+	}
+}
@@ -0,0 +1,226 @@
+package trace
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Inject injects the span context and baggage of `ctx` into the carrier with
+// the propagators configured by `NewProvider`. Use this to propagate traces
+// over transports other than HTTP, such as queues or custom RPC.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// Extract returns a copy of `ctx` holding the remote span context and baggage
+// found in the carrier with the propagators configured by `NewProvider`.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// globalPropagator resolves the global propagator on every call, hence
+// instrumentation created before `NewProvider` is called still uses the
+// configured propagators.
+type globalPropagator struct{}
+
+func (globalPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+func (globalPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+func (globalPropagator) Fields() []string {
+	return otel.GetTextMapPropagator().Fields()
+}
+
+var (
+	_ propagation.TextMapCarrier = MapCarrier{}
+	_ propagation.TextMapCarrier = MultiMapCarrier{}
+	_ propagation.TextMapCarrier = (*BytesHeaderCarrier)(nil)
+	_ propagation.TextMapCarrier = (*EnvCarrier)(nil)
+)
+
+// MapCarrier is a carrier backed by a `map[string]string`.
+type MapCarrier map[string]string
+
+// Get implements `propagation.TextMapCarrier`.
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+// Set implements `propagation.TextMapCarrier`.
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys implements `propagation.TextMapCarrier`.
+func (c MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// MultiMapCarrier is a carrier backed by a `map[string][]string`, such as
+// message headers. Unlike `propagation.HeaderCarrier`, keys are not
+// canonicalised; they are matched case-insensitively instead.
+type MultiMapCarrier map[string][]string
+
+// Get implements `propagation.TextMapCarrier`. It returns the first value of
+// the key.
+func (c MultiMapCarrier) Get(key string) string {
+	values, ok := c[key]
+	if !ok {
+		for k, v := range c {
+			if strings.EqualFold(k, key) {
+				values = v
+				break
+			}
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set implements `propagation.TextMapCarrier`. It replaces all the values of
+// the key, whatever their case.
+func (c MultiMapCarrier) Set(key, value string) {
+	for k := range c {
+		if strings.EqualFold(k, key) {
+			delete(c, k)
+		}
+	}
+	c[key] = []string{value}
+}
+
+// Keys implements `propagation.TextMapCarrier`.
+func (c MultiMapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// BytesHeader is a message header whose key and value are byte slices, as used
+// by most Kafka clients.
+type BytesHeader struct {
+	Key   []byte
+	Value []byte
+}
+
+// BytesHeaderCarrier is a carrier backed by a slice of `BytesHeader`. Since
+// `Set` may grow the slice, the carrier must be used through a pointer and the
+// headers read back from it after injection.
+type BytesHeaderCarrier []BytesHeader
+
+// Get implements `propagation.TextMapCarrier`. It returns the value of the
+// first header with the key.
+func (c *BytesHeaderCarrier) Get(key string) string {
+	for _, h := range *c {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set implements `propagation.TextMapCarrier`. It replaces the value of the
+// first header with the key and removes the others, or appends a new header.
+func (c *BytesHeaderCarrier) Set(key, value string) {
+	headers := (*c)[:0]
+	found := false
+	for _, h := range *c {
+		if string(h.Key) != key {
+			headers = append(headers, h)
+			continue
+		}
+		if !found {
+			found = true
+			headers = append(headers, BytesHeader{Key: h.Key, Value: []byte(value)})
+		}
+	}
+	if !found {
+		headers = append(headers, BytesHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	*c = headers
+}
+
+// Keys implements `propagation.TextMapCarrier`.
+func (c *BytesHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c))
+	for _, h := range *c {
+		keys = append(keys, string(h.Key))
+	}
+	return keys
+}
+
+// EnvCarrier is a carrier backed by a list of environment variables in the
+// "KEY=value" form, such as `os.Environ()` or `exec.Cmd.Env`. Keys are
+// normalised to environment variable names: "traceparent" is stored as
+// "TRACEPARENT" and "x-b3-traceid" as "X_B3_TRACEID". Since `Set` may grow the
+// slice, the carrier must be used through a pointer.
+type EnvCarrier []string
+
+// Get implements `propagation.TextMapCarrier`. It returns the value of the
+// last variable with the key, as `os/exec` does.
+func (c *EnvCarrier) Get(key string) string {
+	prefix := EnvKey(key) + "="
+	for i := len(*c) - 1; i >= 0; i-- {
+		if v, ok := strings.CutPrefix((*c)[i], prefix); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// Set implements `propagation.TextMapCarrier`. It replaces all the variables
+// with the key.
+func (c *EnvCarrier) Set(key, value string) {
+	prefix := EnvKey(key) + "="
+
+	env := (*c)[:0]
+	for _, kv := range *c {
+		if !strings.HasPrefix(kv, prefix) {
+			env = append(env, kv)
+		}
+	}
+	env = append(env, prefix+value)
+	*c = env
+}
+
+// Keys implements `propagation.TextMapCarrier`.
+func (c *EnvCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c))
+	for _, kv := range *c {
+		if k, _, ok := strings.Cut(kv, "="); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// EnvKey returns the environment variable name of a propagation key: letters
+// are upper-cased and any character other than a letter, a digit or an
+// underscore is replaced with an underscore.
+func EnvKey(key string) string {
+	b := []byte(key)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z':
+			b[i] = c - ('a' - 'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,131 @@
+package trace_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestInjectExtract(t *testing.T) {
+	_, cleanup := setupTestTracer()
+	defer cleanup()
+
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	defer otel.SetTextMapPropagator(prev)
+
+	ctx, span := trace.NewSpan(context.Background(), "producer", nil)
+	defer span.End()
+	ctx, err := trace.SetBaggage(ctx, "tenant.id", "acme")
+	if err != nil {
+		t.Fatalf("SetBaggage failed: %v", err)
+	}
+
+	headers := trace.BytesHeaderCarrier{{Key: []byte("content-type"), Value: []byte("application/json")}}
+	env := trace.EnvCarrier{"PATH=/usr/bin"}
+
+	carriers := map[string]propagation.TextMapCarrier{
+		"map":          trace.MapCarrier{},
+		"multi map":    trace.MultiMapCarrier{},
+		"bytes header": &headers,
+		"env":          &env,
+	}
+
+	for name, carrier := range carriers {
+		t.Run(name, func(t *testing.T) {
+			trace.Inject(ctx, carrier)
+
+			extracted := trace.Extract(context.Background(), carrier)
+			if got := trace.SpanFromContext(extracted).SpanContext(); got.SpanID() != span.SpanContext().SpanID() {
+				t.Errorf("expected span ID %s, got %s", span.SpanContext().SpanID(), got.SpanID())
+			}
+			if got := trace.GetBaggage(extracted, "tenant.id"); got != "acme" {
+				t.Errorf("expected baggage tenant.id=%q, got %q", "acme", got)
+			}
+		})
+	}
+
+	if len(headers) != 3 || string(headers[0].Key) != "content-type" {
+		t.Errorf("expected existing header to be kept and 2 headers added, got %v", headers)
+	}
+	if !slices.Contains(env, "PATH=/usr/bin") || !slices.Contains(env.Keys(), "TRACEPARENT") {
+		t.Errorf("expected TRACEPARENT to be added to environment, got %v", env)
+	}
+}
+
+func TestMultiMapCarrier(t *testing.T) {
+	t.Parallel()
+
+	carrier := trace.MultiMapCarrier{"Traceparent": {"first", "second"}}
+
+	if got := carrier.Get("traceparent"); got != "first" {
+		t.Errorf("expected %q, got %q", "first", got)
+	}
+
+	carrier.Set("traceparent", "replaced")
+	if len(carrier) != 1 || carrier.Get("TRACEPARENT") != "replaced" {
+		t.Errorf("expected value to be replaced, got %v", carrier)
+	}
+}
+
+func TestBytesHeaderCarrier(t *testing.T) {
+	t.Parallel()
+
+	carrier := trace.BytesHeaderCarrier{
+		{Key: []byte("traceparent"), Value: []byte("first")},
+		{Key: []byte("traceparent"), Value: []byte("second")},
+	}
+
+	if got := carrier.Get("traceparent"); got != "first" {
+		t.Errorf("expected %q, got %q", "first", got)
+	}
+
+	carrier.Set("traceparent", "replaced")
+	if len(carrier) != 1 || carrier.Get("traceparent") != "replaced" {
+		t.Errorf("expected value to be replaced, got %v", carrier)
+	}
+}
+
+func TestEnvCarrier(t *testing.T) {
+	t.Parallel()
+
+	carrier := trace.EnvCarrier{"X_B3_TRACEID=first", "HOME=/root", "X_B3_TRACEID=second"}
+
+	if got := carrier.Get("x-b3-traceid"); got != "second" {
+		t.Errorf("expected %q, got %q", "second", got)
+	}
+
+	carrier.Set("x-b3-traceid", "replaced")
+	if !slices.Equal(carrier, trace.EnvCarrier{"HOME=/root", "X_B3_TRACEID=replaced"}) {
+		t.Errorf("expected value to be replaced, got %v", carrier)
+	}
+
+	if keys := carrier.Keys(); !slices.Equal(keys, []string{"HOME", "X_B3_TRACEID"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"traceparent":   "TRACEPARENT",
+		"x-b3-traceid":  "X_B3_TRACEID",
+		"uber-trace-id": "UBER_TRACE_ID",
+		"ot.span_id":    "OT_SPAN_ID",
+	}
+
+	for key, want := range tests {
+		if got := trace.EnvKey(key); got != want {
+			t.Errorf("EnvKey(%q): expected %q, got %q", key, want, got)
+		}
+	}
+}
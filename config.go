@@ -1,6 +1,8 @@
 package trace
 
 import (
+	"time"
+
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
@@ -14,6 +16,15 @@ type Config struct {
 	Headers                      map[string]string `env:"OTEL_EXPORTER_OTLP_HEADERS"`
 	LogLevel                     string            `env:"OTEL_LOG_LEVEL,default=info"`
 	Propagators                  []string          `env:"OTEL_PROPAGATORS,default=b3"`
+	ExtractPropagators           []string          `env:"OTEL_PROPAGATORS_EXTRACT"`
+	InjectPropagators            []string          `env:"OTEL_PROPAGATORS_INJECT"`
+	TracerName                   string            `env:"OTEL_TRACER_NAME"`
+	SpanLeakDetection            bool              `env:"OTEL_TRACE_LEAK_DETECTION,default=false"`
+	SpanLeakMaxAge               time.Duration     `env:"OTEL_TRACE_LEAK_MAX_AGE,default=0s"`
+	BaggageSpanAttributes        []string          `env:"OTEL_TRACE_BAGGAGE_ATTRIBUTES"`
+	EnvironmentContext           bool              `env:"OTEL_TRACE_ENVIRONMENT_CONTEXT,default=false"`
+	HTTPExcludedPaths            []string          `env:"OTEL_TRACE_HTTP_EXCLUDED_PATHS"`
+	HTTPExcludedSampleRate       float64           `env:"OTEL_TRACE_HTTP_EXCLUDED_SAMPLE_RATE,default=0"`
 	ResourceAttributes           map[string]string
 	Resource                     *resource.Resource
 	TraceExporter                sdktrace.SpanExporter
@@ -63,6 +74,82 @@ func WithPropagators(propagators []string) Option {
 	}
 }
 
+// WithExtractPropagators configures the propagators used to extract incoming
+// trace context, replacing the ones configured with `WithPropagators`.
+func WithExtractPropagators(propagators []string) Option {
+	return func(c *Config) {
+		c.ExtractPropagators = propagators
+	}
+}
+
+// WithInjectPropagators configures the propagators used to inject outgoing
+// trace context, replacing the ones configured with `WithPropagators`.
+func WithInjectPropagators(propagators []string) Option {
+	return func(c *Config) {
+		c.InjectPropagators = propagators
+	}
+}
+
+// WithTracerName configures the instrumentation scope name used by the
+// package-level span helpers.
+func WithTracerName(name string) Option {
+	return func(c *Config) {
+		c.TracerName = name
+	}
+}
+
+// WithSpanLeakDetection enables the reporting of spans which were started but
+// not ended, see `LeakDetector`. It is meant for development only.
+func WithSpanLeakDetection(enabled bool) Option {
+	return func(c *Config) {
+		c.SpanLeakDetection = enabled
+	}
+}
+
+// WithSpanLeakMaxAge configures the age after which a span which was not ended
+// is reported by the span leak detection. When zero, spans are only reported
+// when the provider shuts down.
+func WithSpanLeakMaxAge(maxAge time.Duration) Option {
+	return func(c *Config) {
+		c.SpanLeakMaxAge = maxAge
+	}
+}
+
+// WithBaggageSpanAttributes configures the baggage members copied onto every
+// span as attributes, see `BaggageSpanProcessor`.
+func WithBaggageSpanAttributes(keys []string) Option {
+	return func(c *Config) {
+		c.BaggageSpanAttributes = keys
+	}
+}
+
+// WithEnvironmentContext configures whether the provider extracts the trace
+// context and baggage of its root context from the TRACEPARENT, TRACESTATE and
+// BAGGAGE environment variables, see `Provider.Context`.
+func WithEnvironmentContext(enabled bool) Option {
+	return func(c *Config) {
+		c.EnvironmentContext = enabled
+	}
+}
+
+// WithHTTPExcludedPaths configures the request paths which the HTTP handlers
+// do not trace, such as "/healthz" or "/static/*.css". Paths are globs when
+// they contain any of the `path.Match` special characters and prefixes
+// otherwise, see `SetDefaultHTTPFilter`.
+func WithHTTPExcludedPaths(paths []string) Option {
+	return func(c *Config) {
+		c.HTTPExcludedPaths = paths
+	}
+}
+
+// WithHTTPExcludedSampleRate configures the fraction of the requests matched
+// by `WithHTTPExcludedPaths` which are traced nonetheless.
+func WithHTTPExcludedSampleRate(rate float64) Option {
+	return func(c *Config) {
+		c.HTTPExcludedSampleRate = rate
+	}
+}
+
 // WithHeaders configures OTLP/gRPC connection headers.
 func WithHeaders(headers map[string]string) Option {
 	return func(c *Config) {
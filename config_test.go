@@ -3,6 +3,7 @@ package trace_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
@@ -207,6 +208,77 @@ func TestWithPropagators(t *testing.T) {
 	}
 }
 
+func TestWithDirectionalPropagators(t *testing.T) {
+	t.Parallel()
+
+	var cfg trace.Config
+	trace.WithExtractPropagators([]string{"b3", "tracecontext"})(&cfg)
+	trace.WithInjectPropagators([]string{"tracecontext"})(&cfg)
+
+	if len(cfg.ExtractPropagators) != 2 {
+		t.Errorf("expected 2 extract propagators, got %d", len(cfg.ExtractPropagators))
+	}
+
+	if len(cfg.InjectPropagators) != 1 || cfg.InjectPropagators[0] != "tracecontext" {
+		t.Errorf("expected InjectPropagators=%v, got %v", []string{"tracecontext"}, cfg.InjectPropagators)
+	}
+}
+
+func TestWithTracerName(t *testing.T) {
+	t.Parallel()
+
+	var cfg trace.Config
+	opt := trace.WithTracerName("example.com/orders")
+	opt(&cfg)
+
+	if cfg.TracerName != "example.com/orders" {
+		t.Errorf("expected TracerName=%q, got %q", "example.com/orders", cfg.TracerName)
+	}
+}
+
+func TestWithSpanLeakDetection(t *testing.T) {
+	t.Parallel()
+
+	var cfg trace.Config
+	trace.WithSpanLeakDetection(true)(&cfg)
+	trace.WithSpanLeakMaxAge(time.Minute)(&cfg)
+
+	if !cfg.SpanLeakDetection {
+		t.Error("expected SpanLeakDetection to be enabled")
+	}
+
+	if cfg.SpanLeakMaxAge != time.Minute {
+		t.Errorf("expected SpanLeakMaxAge=%v, got %v", time.Minute, cfg.SpanLeakMaxAge)
+	}
+}
+
+func TestWithBaggageSpanAttributes(t *testing.T) {
+	t.Parallel()
+
+	var cfg trace.Config
+	trace.WithBaggageSpanAttributes([]string{"tenant.id"})(&cfg)
+
+	if len(cfg.BaggageSpanAttributes) != 1 || cfg.BaggageSpanAttributes[0] != "tenant.id" {
+		t.Errorf("expected BaggageSpanAttributes=%v, got %v", []string{"tenant.id"}, cfg.BaggageSpanAttributes)
+	}
+}
+
+func TestWithHTTPExcludedPaths(t *testing.T) {
+	t.Parallel()
+
+	var cfg trace.Config
+	trace.WithHTTPExcludedPaths([]string{"/healthz"})(&cfg)
+	trace.WithHTTPExcludedSampleRate(0.01)(&cfg)
+
+	if len(cfg.HTTPExcludedPaths) != 1 || cfg.HTTPExcludedPaths[0] != "/healthz" {
+		t.Errorf("expected HTTPExcludedPaths=%v, got %v", []string{"/healthz"}, cfg.HTTPExcludedPaths)
+	}
+
+	if cfg.HTTPExcludedSampleRate != 0.01 {
+		t.Errorf("expected HTTPExcludedSampleRate=%v, got %v", 0.01, cfg.HTTPExcludedSampleRate)
+	}
+}
+
 func TestWithHeaders(t *testing.T) {
 	t.Parallel()
 
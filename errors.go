@@ -0,0 +1,188 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime/debug"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorClassifier decides whether an error recorded with `RecordError` flags
+// the span as "failed". Errors joined with `errors.Join` are classified one by
+// one.
+type ErrorClassifier func(err error) bool
+
+// ExpectedError can be implemented by domain errors, such as validation or
+// not found errors, which are part of the normal operation of a service and
+// must not flag a span as "failed" when `Expected` returns true.
+type ExpectedError interface {
+	error
+	Expected() bool
+}
+
+//nolint:gochecknoglobals // Classifier shared by the package-level error helpers.
+var errorClassifier atomic.Pointer[ErrorClassifier]
+
+// DefaultErrorClassifier flags the span as "failed" unless the error is
+// `context.Canceled` or an `ExpectedError` reporting itself as expected.
+func DefaultErrorClassifier(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var expected ExpectedError
+	if errors.As(err, &expected) && expected.Expected() {
+		return false
+	}
+
+	return true
+}
+
+// SetErrorClassifier replaces the classifier used by `RecordError` when none
+// is given with `WithErrorClassifier`. A nil classifier restores
+// `DefaultErrorClassifier`.
+func SetErrorClassifier(classifier ErrorClassifier) {
+	if classifier == nil {
+		errorClassifier.Store(nil)
+		return
+	}
+	errorClassifier.Store(&classifier)
+}
+
+// ErrorOption configures how `RecordError` records an error.
+type ErrorOption func(*errorConfig)
+
+type errorConfig struct {
+	classifier ErrorClassifier
+	stackTrace bool
+	attributes []attribute.KeyValue
+}
+
+// WithErrorClassifier configures the classifier deciding whether the error
+// flags the span as "failed".
+func WithErrorClassifier(classifier ErrorClassifier) ErrorOption {
+	return func(c *errorConfig) {
+		c.classifier = classifier
+	}
+}
+
+// WithErrorStackTrace records the current stack trace along with the error.
+// Errors reported for panics, see `PanicError`, always carry the stack trace
+// of the panic.
+func WithErrorStackTrace() ErrorOption {
+	return func(c *errorConfig) {
+		c.stackTrace = true
+	}
+}
+
+// WithErrorAttributes adds attributes to the recorded exception events.
+func WithErrorAttributes(attrs ...attribute.KeyValue) ErrorOption {
+	return func(c *errorConfig) {
+		c.attributes = append(c.attributes, attrs...)
+	}
+}
+
+// RecordError adds an "exception" event to the span for the error, or one for
+// each of them when errors were joined with `errors.Join`. The exception type
+// is the type of the innermost error of the chain. Unlike `AddSpanError`, the
+// span is also flagged as "failed" unless the error classifier decides
+// otherwise, see `DefaultErrorClassifier`.
+func RecordError(span trace.Span, err error, opts ...ErrorOption) {
+	if err == nil {
+		return
+	}
+
+	var c errorConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.classifier == nil {
+		c.classifier = currentErrorClassifier()
+	}
+
+	failed := false
+	for _, e := range splitErrors(err) {
+		recordException(span, e, c)
+		if c.classifier(e) {
+			failed = true
+		}
+	}
+
+	if failed {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// currentErrorClassifier returns the classifier set with `SetErrorClassifier`,
+// or `DefaultErrorClassifier`.
+func currentErrorClassifier() ErrorClassifier {
+	if classifier := errorClassifier.Load(); classifier != nil {
+		return *classifier
+	}
+	return DefaultErrorClassifier
+}
+
+func recordException(span trace.Span, err error, c errorConfig) {
+	attrs := []attribute.KeyValue{
+		semconv.ExceptionTypeKey.String(errorType(err)),
+		semconv.ExceptionMessageKey.String(err.Error()),
+	}
+
+	var pe *PanicError
+	switch {
+	case errors.As(err, &pe):
+		attrs = append(attrs, semconv.ExceptionStacktraceKey.String(string(pe.Stack)))
+	case c.stackTrace:
+		attrs = append(attrs, semconv.ExceptionStacktraceKey.String(string(debug.Stack())))
+	}
+
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(append(attrs, c.attributes...)...))
+}
+
+// splitErrors returns the errors joined into `err`, recursively, or `err`
+// itself. The chain of `err` is walked down to the first joined error, so that
+// wrapped joins such as `fmt.Errorf("load: %w", errors.Join(a, b))` are split
+// as well.
+func splitErrors(err error) []error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		joined, ok := e.(interface{ Unwrap() []error }) //nolint:errorlint // The chain is walked by hand.
+		if !ok {
+			continue
+		}
+
+		var errs []error
+		for _, je := range joined.Unwrap() {
+			if je != nil {
+				errs = append(errs, splitErrors(je)...)
+			}
+		}
+		return errs
+	}
+
+	return []error{err}
+}
+
+// errorType returns the type name of the innermost error of the chain.
+func errorType(err error) string {
+	for next := errors.Unwrap(err); next != nil; next = errors.Unwrap(next) {
+		err = next
+	}
+
+	t := reflect.TypeOf(err)
+	prefix := ""
+	if t.Kind() == reflect.Pointer {
+		prefix = "*"
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" || t.Name() == "" {
+		return reflect.TypeOf(err).String()
+	}
+
+	return prefix + t.PkgPath() + "." + t.Name()
+}
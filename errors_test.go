@@ -0,0 +1,208 @@
+package trace_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+type notFoundError struct {
+	id string
+}
+
+func (e *notFoundError) Error() string {
+	return "not found: " + e.id
+}
+
+func (e *notFoundError) Expected() bool {
+	return true
+}
+
+func newRecordedSpan(t *testing.T) (*tracetest.SpanRecorder, oteltrace.Span) {
+	t.Helper()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	_, span := tp.Tracer("test").Start(context.Background(), "test-span")
+	return sr, span
+}
+
+func eventAttribute(event sdktrace.Event, key attribute.Key) (string, bool) {
+	for _, attr := range event.Attributes {
+		if attr.Key == key {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func TestRecordError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records innermost exception type", func(t *testing.T) {
+		t.Parallel()
+
+		sr, span := newRecordedSpan(t)
+		err := fmt.Errorf("loading order: %w", &notFoundError{id: "42"})
+		trace.RecordError(span, err, trace.WithErrorClassifier(func(error) bool { return true }))
+		span.End()
+
+		got := sr.Ended()[0]
+		if got.Status().Code != codes.Error {
+			t.Errorf("expected status code %v, got %v", codes.Error, got.Status().Code)
+		}
+		if got.Status().Description != err.Error() {
+			t.Errorf("expected status description %q, got %q", err.Error(), got.Status().Description)
+		}
+
+		events := got.Events()
+		if len(events) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(events))
+		}
+		want := "*go.pixelfactory.io/pkg/observability/trace_test.notFoundError"
+		if typ, _ := eventAttribute(events[0], "exception.type"); typ != want {
+			t.Errorf("expected exception type %q, got %q", want, typ)
+		}
+		if msg, _ := eventAttribute(events[0], "exception.message"); msg != err.Error() {
+			t.Errorf("expected exception message %q, got %q", err.Error(), msg)
+		}
+		if _, ok := eventAttribute(events[0], "exception.stacktrace"); ok {
+			t.Error("expected no stack trace")
+		}
+	})
+
+	t.Run("records joined errors as multiple events", func(t *testing.T) {
+		t.Parallel()
+
+		sr, span := newRecordedSpan(t)
+		trace.RecordError(span, errors.Join(errors.New("first"), errors.New("second")))
+		span.End()
+
+		events := sr.Ended()[0].Events()
+		if len(events) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(events))
+		}
+		for i, want := range []string{"first", "second"} {
+			if msg, _ := eventAttribute(events[i], "exception.message"); msg != want {
+				t.Errorf("event %d: expected exception message %q, got %q", i, want, msg)
+			}
+		}
+	})
+
+	t.Run("records wrapped joined errors as multiple events", func(t *testing.T) {
+		t.Parallel()
+
+		sr, span := newRecordedSpan(t)
+		err := fmt.Errorf("loading orders: %w", errors.Join(errors.New("first"), &notFoundError{id: "42"}))
+		trace.RecordError(span, err, trace.WithErrorClassifier(func(error) bool { return true }))
+		span.End()
+
+		got := sr.Ended()[0]
+		if got.Status().Description != err.Error() {
+			t.Errorf("expected status description %q, got %q", err.Error(), got.Status().Description)
+		}
+
+		events := got.Events()
+		if len(events) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(events))
+		}
+		want := "*go.pixelfactory.io/pkg/observability/trace_test.notFoundError"
+		if typ, _ := eventAttribute(events[1], "exception.type"); typ != want {
+			t.Errorf("expected exception type %q, got %q", want, typ)
+		}
+		for i, want := range []string{"first", "not found: 42"} {
+			if msg, _ := eventAttribute(events[i], "exception.message"); msg != want {
+				t.Errorf("event %d: expected exception message %q, got %q", i, want, msg)
+			}
+		}
+	})
+
+	t.Run("records stack trace", func(t *testing.T) {
+		t.Parallel()
+
+		sr, span := newRecordedSpan(t)
+		trace.RecordError(span, errors.New("failure"), trace.WithErrorStackTrace())
+		span.End()
+
+		if stack, ok := eventAttribute(sr.Ended()[0].Events()[0], "exception.stacktrace"); !ok || stack == "" {
+			t.Error("expected stack trace")
+		}
+	})
+
+	t.Run("records additional attributes", func(t *testing.T) {
+		t.Parallel()
+
+		sr, span := newRecordedSpan(t)
+		trace.RecordError(span, errors.New("failure"), trace.WithErrorAttributes(attribute.String("order.id", "42")))
+		span.End()
+
+		if id, _ := eventAttribute(sr.Ended()[0].Events()[0], "order.id"); id != "42" {
+			t.Errorf("expected attribute %q, got %q", "42", id)
+		}
+	})
+
+	t.Run("ignores nil error", func(t *testing.T) {
+		t.Parallel()
+
+		sr, span := newRecordedSpan(t)
+		trace.RecordError(span, nil)
+		span.End()
+
+		if len(sr.Ended()[0].Events()) != 0 {
+			t.Error("expected no event")
+		}
+	})
+
+	tests := []struct {
+		name   string
+		err    error
+		status codes.Code
+	}{
+		{name: "unexpected error", err: errors.New("failure"), status: codes.Error},
+		{name: "context canceled", err: fmt.Errorf("query: %w", context.Canceled), status: codes.Unset},
+		{name: "expected error", err: &notFoundError{id: "42"}, status: codes.Unset},
+		{
+			name:   "joined with unexpected error",
+			err:    errors.Join(context.Canceled, errors.New("failure")),
+			status: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("default classifier with "+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sr, span := newRecordedSpan(t)
+			trace.RecordError(span, tt.err, trace.WithErrorClassifier(trace.DefaultErrorClassifier))
+			span.End()
+
+			if code := sr.Ended()[0].Status().Code; code != tt.status {
+				t.Errorf("expected status code %v, got %v", tt.status, code)
+			}
+		})
+	}
+}
+
+func TestSetErrorClassifier(t *testing.T) {
+	trace.SetErrorClassifier(func(error) bool { return false })
+	defer trace.SetErrorClassifier(nil)
+
+	sr, span := newRecordedSpan(t)
+	trace.RecordError(span, errors.New("failure"))
+	span.End()
+
+	if code := sr.Ended()[0].Status().Code; code != codes.Unset {
+		t.Errorf("expected status code %v, got %v", codes.Unset, code)
+	}
+}
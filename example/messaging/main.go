@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+// message is a message of the in-memory queue, carrying the trace context in
+// its headers.
+type message struct {
+	id      string
+	body    []byte
+	headers trace.MapCarrier
+}
+
+// queue is an in-memory queue standing for a message broker.
+type queue struct {
+	name     string
+	messages chan message
+}
+
+func (q *queue) publish(ctx context.Context, id string, body []byte) {
+	msg := message{id: id, body: body, headers: trace.MapCarrier{}}
+
+	// The producer span context is injected into the message headers.
+	_, span := trace.StartProducerSpan(ctx, trace.Message{
+		System:      "memory",
+		Destination: q.name,
+		ID:          msg.id,
+		PayloadSize: len(msg.body),
+		Headers:     msg.headers,
+	})
+	defer span.End()
+
+	q.messages <- msg
+}
+
+func (q *queue) consume(ctx context.Context, handle func(context.Context, message)) {
+	for msg := range q.messages {
+		// The consumer span continues the trace of the producer.
+		msgCtx, span := trace.StartConsumerSpan(ctx, trace.Message{
+			System:      "memory",
+			Destination: q.name,
+			ID:          msg.id,
+			PayloadSize: len(msg.body),
+			Headers:     msg.headers,
+		})
+		handle(msgCtx, msg)
+		span.End()
+	}
+}
+
+func main() {
+	// console exporter.
+	exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// trace provider
+	prv, err := trace.NewProvider(
+		trace.WithTraceEnabled(true),
+		trace.WithTraceExporter(exp),
+		trace.WithServiceName("messaging"),
+	)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer func() {
+		if shutdownErr := prv.Shutdown(); shutdownErr != nil {
+			log.Printf("Failed to shutdown provider: %v", shutdownErr)
+		}
+	}()
+
+	q := &queue{name: "orders", messages: make(chan message)}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.consume(context.Background(), func(ctx context.Context, msg message) {
+			_, span := trace.NewSpan(ctx, "handle-order", nil)
+			defer span.End()
+
+			log.Printf("Processing order %s: %s", msg.id, msg.body)
+		})
+	}()
+
+	ctx, span := trace.NewSpan(context.Background(), "place-orders", nil)
+	for i := range 3 {
+		q.publish(ctx, strconv.Itoa(i), []byte("order #"+strconv.Itoa(i)))
+	}
+	span.End()
+
+	close(q.messages)
+	wg.Wait()
+}
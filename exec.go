@@ -0,0 +1,51 @@
+package trace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// environmentPropagator returns the propagator used for environment
+// variables, which carry the W3C trace context and baggage in the TRACEPARENT,
+// TRACESTATE and BAGGAGE variables whatever propagators are configured.
+func environmentPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// InjectCommand adds the TRACEPARENT, TRACESTATE and BAGGAGE environment
+// variables of the span context and baggage of `ctx` to the command, so that
+// the child process can continue the trace, see `ContextFromEnvironment`. When
+// `cmd.Env` is nil, it is initialised from the current process environment
+// first. Variables inherited from the parent process are removed, so that the
+// child process does not continue another trace when `ctx` holds no span. It
+// must be called before the command is started.
+func InjectCommand(ctx context.Context, cmd *exec.Cmd) {
+	prop := environmentPropagator()
+
+	keys := prop.Fields()
+	for i, key := range keys {
+		keys[i] = EnvKey(key)
+	}
+	env := EnvCarrier(slices.DeleteFunc(cmd.Environ(), func(kv string) bool {
+		key, _, _ := strings.Cut(kv, "=")
+		return slices.Contains(keys, key)
+	}))
+
+	prop.Inject(ctx, &env)
+	cmd.Env = env
+}
+
+// ContextFromEnvironment returns a copy of `ctx` holding the span context and
+// baggage found in the TRACEPARENT, TRACESTATE and BAGGAGE environment
+// variables of the current process. Use this as the root context of a process
+// started with `InjectCommand`, or enable `WithEnvironmentContext` and use
+// `Provider.Context`.
+func ContextFromEnvironment(ctx context.Context) context.Context {
+	env := EnvCarrier(os.Environ())
+	return environmentPropagator().Extract(ctx, &env)
+}
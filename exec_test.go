@@ -0,0 +1,118 @@
+package trace_test
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func newTestTracer(t *testing.T) oteltrace.Tracer {
+	t.Helper()
+
+	tp := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	return tp.Tracer("test")
+}
+
+func TestInjectCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adds trace context to command environment", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, span := newTestTracer(t).Start(context.Background(), "parent")
+		defer span.End()
+
+		ctx, err := trace.SetBaggage(ctx, "job.id", "42")
+		if err != nil {
+			t.Fatalf("SetBaggage failed: %v", err)
+		}
+
+		cmd := exec.CommandContext(ctx, "true")
+		cmd.Env = []string{"HOME=/root", "TRACEPARENT=stale"}
+		trace.InjectCommand(ctx, cmd)
+
+		want := "TRACEPARENT=00-" + span.SpanContext().TraceID().String() + "-" +
+			span.SpanContext().SpanID().String() + "-01"
+		if !slices.Contains(cmd.Env, want) {
+			t.Errorf("expected %q in environment, got %v", want, cmd.Env)
+		}
+		if !slices.Contains(cmd.Env, "BAGGAGE=job.id=42") {
+			t.Errorf("expected %q in environment, got %v", "BAGGAGE=job.id=42", cmd.Env)
+		}
+		if !slices.Contains(cmd.Env, "HOME=/root") || slices.Contains(cmd.Env, "TRACEPARENT=stale") {
+			t.Errorf("unexpected environment %v", cmd.Env)
+		}
+	})
+
+	t.Run("inherits process environment", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, span := newTestTracer(t).Start(context.Background(), "parent")
+		defer span.End()
+
+		cmd := exec.CommandContext(ctx, "true")
+		trace.InjectCommand(ctx, cmd)
+
+		if !slices.ContainsFunc(cmd.Env, func(kv string) bool { return strings.HasPrefix(kv, "PATH=") }) {
+			t.Errorf("expected PATH to be inherited, got %v", cmd.Env)
+		}
+	})
+
+	t.Run("drops inherited trace context without span", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := exec.CommandContext(context.Background(), "true")
+		cmd.Env = []string{
+			"HOME=/root",
+			"TRACEPARENT=00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			"TRACESTATE=vendor=value",
+			"BAGGAGE=job.id=41",
+		}
+		trace.InjectCommand(context.Background(), cmd)
+
+		if !slices.Equal(cmd.Env, []string{"HOME=/root"}) {
+			t.Errorf("environment = %v, want [HOME=/root]", cmd.Env)
+		}
+	})
+}
+
+func TestInjectCommandInheritedTraceContext(t *testing.T) {
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	t.Setenv("BAGGAGE", "job.id=41")
+
+	cmd := exec.CommandContext(context.Background(), "true")
+	trace.InjectCommand(context.Background(), cmd)
+
+	for _, kv := range cmd.Env {
+		if strings.HasPrefix(kv, "TRACEPARENT=") || strings.HasPrefix(kv, "BAGGAGE=") {
+			t.Errorf("unexpected inherited variable %q", kv)
+		}
+	}
+}
+
+func TestContextFromEnvironment(t *testing.T) {
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	t.Setenv("BAGGAGE", "job.id=42")
+
+	ctx := trace.ContextFromEnvironment(context.Background())
+
+	sc := trace.SpanFromContext(ctx).SpanContext()
+	if sc.TraceID().String() != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("expected trace ID %q, got %q", "0af7651916cd43dd8448eb211c80319c", sc.TraceID())
+	}
+	if !sc.IsRemote() {
+		t.Error("expected remote span context")
+	}
+	if got := trace.GetBaggage(ctx, "job.id"); got != "42" {
+		t.Errorf("expected baggage job.id=%q, got %q", "42", got)
+	}
+}
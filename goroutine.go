@@ -0,0 +1,111 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+// PanicError is the error reported by goroutines started with `Go` or
+// `Group.Go` when their function panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Go runs `fn` in a new goroutine under a child span of the current span of
+// `ctx`. The span is ended when `fn` returns. A returned error or a panic is
+// recorded against the span with `RecordError`. Panics are recovered so that
+// they do not crash the process.
+func Go(ctx context.Context, name string, fn func(context.Context) error) {
+	go func() {
+		_ = runSpan(ctx, name, fn)
+	}()
+}
+
+// Group is a traced `errgroup.Group`. Each task started with `Go` runs under
+// its own child span, and the span of the context passed to `NewGroup` is
+// flagged as "failed" when the group fails.
+type Group struct {
+	group  *errgroup.Group
+	ctx    context.Context
+	parent trace.Span
+}
+
+// NewGroup returns a new `Group` and an associated context derived from
+// `ctx`. Like `errgroup.WithContext`, the derived context is cancelled the
+// first time a task returns an error or the first time `Wait` returns.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	g, gctx := errgroup.WithContext(ctx)
+
+	return &Group{
+		group:  g,
+		ctx:    gctx,
+		parent: trace.SpanFromContext(ctx),
+	}, gctx
+}
+
+// Go runs `fn` in a new goroutine under a child span named `name`. See
+// `errgroup.Group.Go`.
+func (g *Group) Go(name string, fn func(context.Context) error) {
+	g.group.Go(func() error {
+		return runSpan(g.ctx, name, fn)
+	})
+}
+
+// TryGo runs `fn` in a new goroutine only if the number of active goroutines
+// in the group is below the configured limit. See `errgroup.Group.TryGo`.
+func (g *Group) TryGo(name string, fn func(context.Context) error) bool {
+	return g.group.TryGo(func() error {
+		return runSpan(g.ctx, name, fn)
+	})
+}
+
+// SetLimit limits the number of active goroutines in the group. See
+// `errgroup.Group.SetLimit`.
+func (g *Group) SetLimit(n int) {
+	g.group.SetLimit(n)
+}
+
+// Wait blocks until all tasks have returned, then returns the first non-nil
+// error from them, if any. The error is recorded against the parent span with
+// `RecordError`.
+func (g *Group) Wait() error {
+	err := g.group.Wait()
+	RecordError(g.parent, err)
+
+	return err
+}
+
+func runSpan(ctx context.Context, name string, fn func(context.Context) error) error {
+	ctx, span := NewSpan(ctx, name, nil)
+	defer span.End()
+
+	err := callRecovered(ctx, fn)
+	RecordError(span, err)
+
+	return err
+}
+
+func callRecovered(ctx context.Context, fn func(context.Context) error) error {
+	var err error
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+
+		err = fn(ctx)
+	}()
+
+	return err
+}
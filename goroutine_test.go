@@ -0,0 +1,142 @@
+package trace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func waitForSpans(t *testing.T, sr *tracetest.SpanRecorder, n int) []sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if spans := sr.Ended(); len(spans) >= n {
+			return spans
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("expected %d spans, got %d", n, len(sr.Ended()))
+	return nil
+}
+
+func TestGo(t *testing.T) {
+	t.Run("runs function under child span", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		ctx, parent := trace.NewSpan(context.Background(), "parent", nil)
+		defer parent.End()
+
+		trace.Go(ctx, "task", func(ctx context.Context) error {
+			if trace.SpanFromContext(ctx).SpanContext().SpanID() == parent.SpanContext().SpanID() {
+				t.Error("expected task to run under its own span")
+			}
+			return nil
+		})
+
+		spans := waitForSpans(t, sr, 1)
+		if spans[0].Name() != "task" {
+			t.Errorf("expected span name %q, got %q", "task", spans[0].Name())
+		}
+		if spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Error("expected task span to be a child of parent span")
+		}
+		if spans[0].Status().Code != codes.Unset {
+			t.Errorf("expected status code %v, got %v", codes.Unset, spans[0].Status().Code)
+		}
+	})
+
+	t.Run("records panics", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		trace.Go(context.Background(), "panicking-task", func(context.Context) error {
+			panic("boom")
+		})
+
+		spans := waitForSpans(t, sr, 1)
+		if spans[0].Status().Code != codes.Error {
+			t.Errorf("expected status code %v, got %v", codes.Error, spans[0].Status().Code)
+		}
+		if spans[0].Status().Description != "panic: boom" {
+			t.Errorf("expected status description %q, got %q", "panic: boom", spans[0].Status().Description)
+		}
+		if len(spans[0].Events()) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(spans[0].Events()))
+		}
+	})
+}
+
+func TestGroup(t *testing.T) {
+	t.Run("all tasks succeed", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		ctx, parent := trace.NewSpan(context.Background(), "parent", nil)
+
+		g, _ := trace.NewGroup(ctx)
+		g.SetLimit(2)
+		g.Go("task-1", func(context.Context) error { return nil })
+		g.Go("task-2", func(context.Context) error { return nil })
+
+		if err := g.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		parent.End()
+
+		spans := sr.Ended()
+		if len(spans) != 3 {
+			t.Fatalf("expected 3 spans, got %d", len(spans))
+		}
+		for _, span := range spans[:2] {
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("expected span %q to be a child of parent span", span.Name())
+			}
+		}
+		if spans[2].Status().Code != codes.Unset {
+			t.Errorf("expected parent status code %v, got %v", codes.Unset, spans[2].Status().Code)
+		}
+	})
+
+	t.Run("failing task fails parent", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		ctx, parent := trace.NewSpan(context.Background(), "parent", nil)
+
+		testErr := errors.New("task failed")
+		g, gctx := trace.NewGroup(ctx)
+		g.Go("failing", func(context.Context) error { return testErr })
+		g.Go("panicking", func(context.Context) error {
+			<-gctx.Done()
+			panic("boom")
+		})
+
+		if err := g.Wait(); !errors.Is(err, testErr) {
+			t.Fatalf("expected error %v, got %v", testErr, err)
+		}
+		parent.End()
+
+		spans := sr.Ended()
+		if len(spans) != 3 {
+			t.Fatalf("expected 3 spans, got %d", len(spans))
+		}
+		for _, span := range spans {
+			if span.Status().Code != codes.Error {
+				t.Errorf("expected span %q status code %v, got %v", span.Name(), codes.Error, span.Status().Code)
+			}
+		}
+		if spans[2].Status().Description != testErr.Error() {
+			t.Errorf("expected parent status description %q, got %q", testErr.Error(), spans[2].Status().Description)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package trace
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// GRPCOption configures the gRPC instrumentation helpers.
+type GRPCOption func(*grpcConfig)
+
+type grpcConfig struct {
+	filters []func(fullMethod string) bool
+	otel    []otelgrpc.Option
+}
+
+func newGRPCConfig(opts []GRPCOption) *grpcConfig {
+	c := &grpcConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// options returns the `otelgrpc` options of the configuration.
+func (c *grpcConfig) options() []otelgrpc.Option {
+	opts := []otelgrpc.Option{
+		otelgrpc.WithTracerProvider(globalTracerProvider{}),
+		otelgrpc.WithPropagators(globalPropagator{}),
+	}
+	if len(c.filters) > 0 {
+		opts = append(opts, otelgrpc.WithFilter(c.traced))
+	}
+
+	return append(opts, c.otel...)
+}
+
+// traced reports whether the RPC is traced according to the method filters.
+func (c *grpcConfig) traced(info *stats.RPCTagInfo) bool {
+	for _, filter := range c.filters {
+		if !filter(info.FullMethodName) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithGRPCMethodFilter configures a function deciding whether an RPC is
+// traced after its full method name, such as "/package.Service/Method". RPCs
+// for which any of the filters returns false are not traced.
+func WithGRPCMethodFilter(fn func(fullMethod string) bool) GRPCOption {
+	return func(c *grpcConfig) {
+		c.filters = append(c.filters, fn)
+	}
+}
+
+// WithGRPCExcludedMethods configures full method names which are not traced,
+// such as "/grpc.health.v1.Health/Check".
+func WithGRPCExcludedMethods(methods ...string) GRPCOption {
+	return WithGRPCMethodFilter(func(fullMethod string) bool {
+		return !slices.Contains(methods, fullMethod)
+	})
+}
+
+// WithGRPCMessageEvents records an event each time a message is received or
+// sent, along with its size.
+func WithGRPCMessageEvents(received, sent bool) GRPCOption {
+	var events []otelgrpc.Event
+	if received {
+		events = append(events, otelgrpc.ReceivedEvents)
+	}
+	if sent {
+		events = append(events, otelgrpc.SentEvents)
+	}
+
+	return WithOtelGRPCOptions(otelgrpc.WithMessageEvents(events...))
+}
+
+// WithOtelGRPCOptions passes options to the underlying `otelgrpc` stats
+// handlers. They are applied last, hence take precedence over the ones set by
+// this package.
+func WithOtelGRPCOptions(opts ...otelgrpc.Option) GRPCOption {
+	return func(c *grpcConfig) {
+		c.otel = append(c.otel, opts...)
+	}
+}
+
+// GRPCServerOptions returns the options attaching tracing functionality to a
+// gRPC server. Spans are created with the tracer provider and the propagators
+// configured by `NewProvider`.
+func GRPCServerOptions(opts ...GRPCOption) []grpc.ServerOption {
+	c := newGRPCConfig(opts)
+
+	return []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(c.options()...)),
+	}
+}
+
+// GRPCDialOptions returns the options attaching tracing functionality to a
+// gRPC client connection. Spans are created with the tracer provider and the
+// propagators configured by `NewProvider`.
+func GRPCDialOptions(opts ...GRPCOption) []grpc.DialOption {
+	c := newGRPCConfig(opts)
+
+	return []grpc.DialOption{
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(c.options()...)),
+	}
+}
@@ -0,0 +1,107 @@
+package trace_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+// checkHealth calls the health service of an in-process server instrumented
+// with the options, and returns once both ends were ended.
+func checkHealth(t *testing.T, opts ...trace.GRPCOption) {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(trace.GRPCServerOptions(opts...)...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() {
+		_ = server.Serve(lis)
+	}()
+
+	dialOpts := append(trace.GRPCDialOptions(opts...),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	conn.Close()
+	server.GracefulStop()
+}
+
+func spansByKind(sr *tracetest.SpanRecorder) map[oteltrace.SpanKind][]string {
+	kinds := map[oteltrace.SpanKind][]string{}
+	for _, span := range sr.Ended() {
+		kinds[span.SpanKind()] = append(kinds[span.SpanKind()], span.Name())
+	}
+	return kinds
+}
+
+func TestGRPCOptions(t *testing.T) {
+	t.Run("traces client and server", func(t *testing.T) {
+		sr := setupHTTPTestTracer(t)
+		checkHealth(t)
+
+		var client, server oteltrace.SpanContext
+		var parent oteltrace.SpanContext
+		for _, span := range sr.Ended() {
+			switch span.SpanKind() {
+			case oteltrace.SpanKindClient:
+				client = span.SpanContext()
+			case oteltrace.SpanKindServer:
+				server = span.SpanContext()
+				parent = span.Parent()
+			}
+		}
+
+		if !client.IsValid() || !server.IsValid() {
+			t.Fatalf("expected client and server spans, got %v", spansByKind(sr))
+		}
+		if server.TraceID() != client.TraceID() || parent.SpanID() != client.SpanID() {
+			t.Error("expected the server span to be a child of the client span")
+		}
+	})
+
+	t.Run("filters methods", func(t *testing.T) {
+		sr := setupHTTPTestTracer(t)
+		checkHealth(t, trace.WithGRPCExcludedMethods("/grpc.health.v1.Health/Check"))
+
+		if spans := sr.Ended(); len(spans) != 0 {
+			t.Errorf("expected no spans, got %v", spansByKind(sr))
+		}
+	})
+
+	t.Run("records message events", func(t *testing.T) {
+		sr := setupHTTPTestTracer(t)
+		checkHealth(t, trace.WithGRPCMessageEvents(true, true))
+
+		spans := sr.Ended()
+		if len(spans) != 2 {
+			t.Fatalf("expected 2 spans, got %d", len(spans))
+		}
+		for _, span := range spans {
+			if len(span.Events()) != 2 {
+				t.Errorf("expected 2 message events on %s span, got %d", span.SpanKind(), len(span.Events()))
+			}
+		}
+	})
+}
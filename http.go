@@ -4,23 +4,143 @@ import (
 	"net/http"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
-	"go.opentelemetry.io/otel"
 )
 
+// HTTPOption configures the HTTP instrumentation helpers. Options which do not
+// apply to a helper are ignored by it.
+type HTTPOption func(*httpConfig)
+
+type httpConfig struct {
+	trust    trustConfig
+	route    routeConfig
+	filter   filterConfig
+	header   headerConfig
+	body     bodyConfig
+	response responseConfig
+	timing   timingConfig
+	client   clientConfig
+	panics   recoverConfig
+	otel     []otelhttp.Option
+}
+
+func newHTTPConfig(opts []HTTPOption) *httpConfig {
+	c := &httpConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// handler returns the handler instrumented according to the configuration.
+func (c *httpConfig) handler(handler http.Handler, name string) http.Handler {
+	opts := []otelhttp.Option{otelhttp.WithTracerProvider(globalTracerProvider{})}
+	opts = append(opts, c.trust.handlerOptions()...)
+	opts = append(opts, c.route.handlerOptions()...)
+	opts = append(opts, c.filter.handlerOptions()...)
+	opts = append(opts, c.otel...)
+
+	handler = c.panics.wrap(handler)
+	handler = c.body.wrap(handler)
+	handler = c.header.wrap(handler)
+	handler = c.route.wrap(handler)
+	handler = c.response.wrap(handler)
+
+	return c.trust.wrap(otelhttp.NewHandler(handler, name, opts...))
+}
+
+// transport returns the round tripper instrumented according to the
+// configuration.
+func (c *httpConfig) transport(rt http.RoundTripper) http.RoundTripper {
+	opts := []otelhttp.Option{
+		otelhttp.WithTracerProvider(globalTracerProvider{}),
+		otelhttp.WithPropagators(globalPropagator{}),
+	}
+	opts = append(opts, c.otel...)
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	rt = c.header.wrapTransport(rt)
+	rt = c.body.wrapTransport(rt)
+	rt = c.timing.wrapTransport(rt)
+
+	return c.body.wrapClient(otelhttp.NewTransport(rt, opts...))
+}
+
+// roundTripperFunc is an adapter to use a function as a `http.RoundTripper`.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// WithOtelHTTPOptions passes options to the underlying `otelhttp` handler or
+// transport. They are applied last, hence take precedence over the ones set by
+// this package.
+func WithOtelHTTPOptions(opts ...otelhttp.Option) HTTPOption {
+	return func(c *httpConfig) {
+		c.otel = append(c.otel, opts...)
+	}
+}
+
+// WithRequestFilter configures a function deciding whether a request is
+// traced. Requests for which any of the filters returns false are not traced.
+func WithRequestFilter(fn func(*http.Request) bool) HTTPOption {
+	return WithOtelHTTPOptions(otelhttp.WithFilter(fn))
+}
+
+// WithSpanNameFormatter configures the function naming the spans after the
+// operation name given to the helper and the request.
+func WithSpanNameFormatter(fn func(operation string, r *http.Request) string) HTTPOption {
+	return WithOtelHTTPOptions(otelhttp.WithSpanNameFormatter(fn))
+}
+
+// WithPublicEndpoint configures the handlers to start a new trace for every
+// request, linked to the remote span context if any, instead of continuing the
+// caller's trace. See `WithTrustPolicy` for finer control.
+func WithPublicEndpoint() HTTPOption {
+	return WithOtelHTTPOptions(otelhttp.WithPublicEndpoint())
+}
+
+// WithMessageEvents records an event each time the request body is read or the
+// response body is written, along with the number of bytes, for handlers.
+func WithMessageEvents(read, write bool) HTTPOption {
+	var events []otelhttp.Event
+	if read {
+		events = append(events, otelhttp.ReadEvents)
+	}
+	if write {
+		events = append(events, otelhttp.WriteEvents)
+	}
+
+	return WithOtelHTTPOptions(otelhttp.WithMessageEvents(events...))
+}
+
 // HTTPHandler is a convenience function which helps attaching tracing
 // functionality to conventional HTTP handlers.
-func HTTPHandler(handler http.Handler, name string) http.Handler {
-	return otelhttp.NewHandler(handler, name, otelhttp.WithTracerProvider(otel.GetTracerProvider()))
+func HTTPHandler(handler http.Handler, name string, opts ...HTTPOption) http.Handler {
+	return newHTTPConfig(opts).handler(handler, name)
 }
 
 // HTTPHandlerFunc is a convenience function which helps attaching tracing
 // functionality to conventional HTTP handlers.
-func HTTPHandlerFunc(handler http.HandlerFunc, name string) http.HandlerFunc {
-	return otelhttp.NewHandler(handler, name, otelhttp.WithTracerProvider(otel.GetTracerProvider())).ServeHTTP
+func HTTPHandlerFunc(handler http.HandlerFunc, name string, opts ...HTTPOption) http.HandlerFunc {
+	return newHTTPConfig(opts).handler(handler, name).ServeHTTP
+}
+
+// Middleware returns a middleware attaching tracing functionality to the
+// handlers it wraps, for routers accepting `func(http.Handler) http.Handler`
+// middlewares. Spans are named after the route, see `HTTPRouteHandler`.
+func Middleware(opts ...HTTPOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return HTTPRouteHandler(next, opts...)
+	}
 }
 
 // HTTPClientTransporter is a convenience function which helps attaching tracing
-// functionality to conventional HTTP clients.
-func HTTPClientTransporter(rt http.RoundTripper) http.RoundTripper {
-	return otelhttp.NewTransport(rt)
+// functionality to conventional HTTP clients. Spans are created with the tracer
+// provider and the propagators configured by `NewProvider`.
+func HTTPClientTransporter(rt http.RoundTripper, opts ...HTTPOption) http.RoundTripper {
+	return newHTTPConfig(opts).transport(rt)
 }
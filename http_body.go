@@ -0,0 +1,293 @@
+package trace
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultBodyCaptureBytes is the number of bytes captured from each body when
+// `HTTPBodyCapture.MaxBytes` is zero.
+const DefaultBodyCaptureBytes = 4096
+
+// HTTPBodyCapture configures the recording of request and response bodies on
+// the spans, see `WithBodyCapture`. Bodies are recorded as "http.request.body"
+// and "http.response.body" events.
+type HTTPBodyCapture struct {
+	// MaxBytes is the number of bytes captured from each body, the remainder
+	// being streamed as is.
+	MaxBytes int
+	// ContentTypes are the media types of the captured bodies, such as
+	// "application/json" or "text/*". Bodies of any type are captured when
+	// empty.
+	ContentTypes []string
+	// Redact is called with the captured body before it is recorded, to mask
+	// secrets or personal data. The returned body is recorded instead.
+	Redact func(contentType string, body []byte) []byte
+	// SampledOnly restricts the capture to sampled spans.
+	SampledOnly bool
+}
+
+type bodyConfig struct {
+	capture *HTTPBodyCapture
+}
+
+// WithBodyCapture configures handlers and transports to record up to
+// `MaxBytes` of the request and response bodies on the spans. It is meant for
+// debugging specific endpoints: bodies often hold personal data, see
+// `HTTPBodyCapture.Redact`.
+func WithBodyCapture(capture HTTPBodyCapture) HTTPOption {
+	if capture.MaxBytes <= 0 {
+		capture.MaxBytes = DefaultBodyCaptureBytes
+	}
+
+	return func(c *httpConfig) {
+		c.body.capture = &capture
+	}
+}
+
+// enabled reports whether the bodies of the span's request are captured.
+func (c bodyConfig) enabled(span trace.Span) bool {
+	if c.capture == nil || !span.IsRecording() {
+		return false
+	}
+	return !c.capture.SampledOnly || span.SpanContext().IsSampled()
+}
+
+// wrap records the bodies on the span of the request once it was served.
+func (c bodyConfig) wrap(handler http.Handler) http.Handler {
+	if c.capture == nil {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		if !c.enabled(span) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		var request *bodyRecorder
+		if r.Body != nil && r.Body != http.NoBody {
+			request = c.newRecorder(span, "http.request.body")
+			r.Body = &bodyReader{ReadCloser: r.Body, recorder: request}
+		}
+
+		response := c.newRecorder(span, "http.response.body")
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(p []byte) (int, error) {
+					n, err := next(p)
+					_, _ = response.Write(p[:n])
+					return n, err
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					return next(io.TeeReader(src, response))
+				}
+			},
+		})
+
+		handler.ServeHTTP(w, r)
+
+		if request != nil {
+			request.record(r.Header.Get("Content-Type"))
+		}
+		response.record(w.Header().Get("Content-Type"))
+	})
+}
+
+type bodyRecorderKey struct{}
+
+// wrapClient records the response body before the client span ends, when the
+// response body is closed before being read entirely. It wraps the `otelhttp`
+// transport, which ends the span.
+func (c bodyConfig) wrapClient(rt http.RoundTripper) http.RoundTripper {
+	if c.capture == nil {
+		return rt
+	}
+
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		var response *bodyRecorder
+		r = r.WithContext(context.WithValue(r.Context(), bodyRecorderKey{}, &response))
+
+		resp, err := rt.RoundTrip(r)
+		if err == nil && response != nil {
+			resp.Body = &bodyCloser{ReadCloser: resp.Body, close: func() {
+				response.record(resp.Header.Get("Content-Type"))
+			}}
+		}
+
+		return resp, err
+	})
+}
+
+// wrapTransport records the request body once the request was sent, and the
+// response body once it was read entirely.
+func (c bodyConfig) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if c.capture == nil {
+		return rt
+	}
+
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		span := trace.SpanFromContext(r.Context())
+		if !c.enabled(span) {
+			return rt.RoundTrip(r)
+		}
+
+		var request *bodyRecorder
+		if r.Body != nil && r.Body != http.NoBody {
+			request = c.newRecorder(span, "http.request.body")
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.Body = &bodyReader{ReadCloser: r.Body, recorder: request}
+			r = r2
+		}
+
+		resp, err := rt.RoundTrip(r)
+		if request != nil {
+			request.record(r.Header.Get("Content-Type"))
+		}
+		if err != nil || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+			return resp, err
+		}
+
+		response := c.newRecorder(span, "http.response.body")
+		resp.Body = &bodyReader{ReadCloser: resp.Body, recorder: response, eof: func() {
+			response.record(resp.Header.Get("Content-Type"))
+		}}
+		if ref, ok := r.Context().Value(bodyRecorderKey{}).(**bodyRecorder); ok {
+			*ref = response
+		}
+
+		return resp, nil
+	})
+}
+
+func (c bodyConfig) newRecorder(span trace.Span, event string) *bodyRecorder {
+	return &bodyRecorder{
+		capture: c.capture,
+		span:    span,
+		event:   event,
+	}
+}
+
+// bodyRecorder captures the first bytes written to it and records them once
+// as a span event.
+type bodyRecorder struct {
+	capture *HTTPBodyCapture
+	span    trace.Span
+	event   string
+
+	mu        sync.Mutex
+	buf       []byte
+	truncated bool
+	recorded  bool
+}
+
+// Write implements `io.Writer`. It never fails.
+func (b *bodyRecorder) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := min(len(p), b.capture.MaxBytes-len(b.buf))
+	b.buf = append(b.buf, p[:n]...)
+	if n < len(p) {
+		b.truncated = true
+	}
+
+	return len(p), nil
+}
+
+// record adds the captured body to the span unless it is empty, its content
+// type is not allowed, or it was already recorded. The content type is
+// detected from the body when unknown.
+func (b *bodyRecorder) record(contentType string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.recorded || len(b.buf) == 0 {
+		return
+	}
+	b.recorded = true
+
+	if len(contentType) == 0 {
+		contentType = http.DetectContentType(b.buf)
+	}
+	if !allowedContentType(b.capture.ContentTypes, contentType) {
+		return
+	}
+
+	body := b.buf
+	if b.capture.Redact != nil {
+		body = b.capture.Redact(contentType, body)
+	}
+
+	b.span.AddEvent(b.event, trace.WithAttributes(
+		attribute.String("http.body.content", string(body)),
+		attribute.Bool("http.body.truncated", b.truncated),
+	))
+}
+
+// allowedContentType reports whether the media type of the content type
+// matches any of the allowed ones, which may be wildcards such as "text/*".
+func allowedContentType(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, a := range allowed {
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if strings.EqualFold(a, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bodyReader copies what is read from a body to a recorder, and calls `eof`
+// once the body was read entirely.
+type bodyReader struct {
+	io.ReadCloser
+
+	recorder *bodyRecorder
+	eof      func()
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	_, _ = r.recorder.Write(p[:n])
+	if err == io.EOF && r.eof != nil { //nolint:errorlint // Read returns io.EOF itself.
+		r.eof()
+	}
+	return n, err
+}
+
+// bodyCloser calls `close` before closing a body.
+type bodyCloser struct {
+	io.ReadCloser
+
+	close func()
+}
+
+func (c *bodyCloser) Close() error {
+	c.close()
+	return c.ReadCloser.Close()
+}
@@ -0,0 +1,217 @@
+package trace_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+// bodyEvent returns the content and the truncation flag of the body event.
+func bodyEvent(span sdktrace.ReadOnlySpan, name string) (string, bool, bool) {
+	for _, event := range span.Events() {
+		if event.Name != name {
+			continue
+		}
+
+		var content string
+		var truncated bool
+		for _, attr := range event.Attributes {
+			switch attr.Key {
+			case "http.body.content":
+				content = attr.Value.AsString()
+			case "http.body.truncated":
+				truncated = attr.Value.AsBool()
+			}
+		}
+		return content, truncated, true
+	}
+	return "", false, false
+}
+
+func echoHandler(contentType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = io.Copy(w, r.Body)
+	})
+}
+
+func TestHTTPHandlerBodyCapture(t *testing.T) {
+	tests := []struct {
+		name        string
+		capture     trace.HTTPBodyCapture
+		contentType string
+		body        string
+		content     string
+		truncated   bool
+		notCaptured bool
+	}{
+		{
+			name:        "captures bodies",
+			contentType: "application/json",
+			body:        `{"id":42}`,
+			content:     `{"id":42}`,
+		},
+		{
+			name:        "truncates bodies",
+			capture:     trace.HTTPBodyCapture{MaxBytes: 4},
+			contentType: "text/plain",
+			body:        "hello world",
+			content:     "hell",
+			truncated:   true,
+		},
+		{
+			name:        "captures allowed content types",
+			capture:     trace.HTTPBodyCapture{ContentTypes: []string{"text/*"}},
+			contentType: "text/plain; charset=utf-8",
+			body:        "hello",
+			content:     "hello",
+		},
+		{
+			name:        "ignores other content types",
+			capture:     trace.HTTPBodyCapture{ContentTypes: []string{"application/json"}},
+			contentType: "application/octet-stream",
+			body:        "hello",
+			notCaptured: true,
+		},
+		{
+			name: "redacts bodies",
+			capture: trace.HTTPBodyCapture{Redact: func(_ string, body []byte) []byte {
+				return bytes.ReplaceAll(body, []byte("secret"), []byte("***"))
+			}},
+			contentType: "text/plain",
+			body:        "password=secret",
+			content:     "password=***",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			handler := trace.HTTPHandler(echoHandler(tt.contentType), "operation", trace.WithBodyCapture(tt.capture))
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Body.String() != tt.body {
+				t.Errorf("expected response body %q, got %q", tt.body, rec.Body.String())
+			}
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			for _, event := range []string{"http.request.body", "http.response.body"} {
+				content, truncated, ok := bodyEvent(spans[0], event)
+				if ok == tt.notCaptured {
+					t.Fatalf("expected %s event=%v, got %v", event, !tt.notCaptured, ok)
+				}
+				if content != tt.content || truncated != tt.truncated {
+					t.Errorf("expected %s=%q truncated=%v, got %q truncated=%v",
+						event, tt.content, tt.truncated, content, truncated)
+				}
+			}
+		})
+	}
+}
+
+type recordOnlySampler struct{}
+
+func (recordOnlySampler) ShouldSample(sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordOnly}
+}
+
+func (recordOnlySampler) Description() string {
+	return "RecordOnly"
+}
+
+func TestHTTPHandlerBodyCaptureSampledOnly(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(recordOnlySampler{}), sdktrace.WithSpanProcessor(sr))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	handler := trace.HTTPHandler(echoHandler("text/plain"), "operation",
+		trace.WithBodyCapture(trace.HTTPBodyCapture{SampledOnly: true}),
+	)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello")))
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if len(spans[0].Events()) > 0 {
+		t.Errorf("expected no events on unsampled span, got %v", spans[0].Events())
+	}
+}
+
+func TestHTTPClientTransporterBodyCapture(t *testing.T) {
+	server := httptest.NewServer(echoHandler("text/plain"))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		read    func(io.Reader) error
+		content string
+	}{
+		{
+			name: "records response read entirely",
+			read: func(r io.Reader) error {
+				_, err := io.ReadAll(r)
+				return err
+			},
+			content: "hello world",
+		},
+		{
+			name: "records response closed early",
+			read: func(r io.Reader) error {
+				_, err := io.ReadFull(r, make([]byte, 5))
+				return err
+			},
+			content: "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			client := &http.Client{
+				Transport: trace.HTTPClientTransporter(nil, trace.WithBodyCapture(trace.HTTPBodyCapture{})),
+			}
+
+			resp, err := client.Post(server.URL, "text/plain", strings.NewReader("hello world"))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if err := tt.read(resp.Body); err != nil {
+				t.Fatalf("failed to read response: %v", err)
+			}
+			resp.Body.Close()
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			if content, _, _ := bodyEvent(spans[0], "http.request.body"); content != "hello world" {
+				t.Errorf("expected request body %q, got %q", "hello world", content)
+			}
+			if content, _, _ := bodyEvent(spans[0], "http.response.body"); content != tt.content {
+				t.Errorf("expected response body %q, got %q", tt.content, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package trace
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultHTTPClientTimeout is the timeout of the clients returned by
+// `NewHTTPClient` unless configured with `WithClientTimeout`.
+const DefaultHTTPClientTimeout = 30 * time.Second
+
+type clientConfig struct {
+	timeout time.Duration
+	base    http.RoundTripper
+}
+
+// WithClientTimeout configures the time limit of the requests made by the
+// clients returned by `NewHTTPClient`, including reading the response body. A
+// zero timeout means no timeout.
+func WithClientTimeout(timeout time.Duration) HTTPOption {
+	return func(c *httpConfig) {
+		c.client.timeout = timeout
+	}
+}
+
+// WithBaseTransport configures the transport wrapped by the clients returned
+// by `NewHTTPClient`, `http.DefaultTransport` by default.
+func WithBaseTransport(rt http.RoundTripper) HTTPOption {
+	return func(c *httpConfig) {
+		c.client.base = rt
+	}
+}
+
+// NewHTTPClient returns a new HTTP client whose requests are traced. Spans are
+// named after the method and the host of the request, "GET api.example.com"
+// for instance, unless configured with `WithSpanNameFormatter`.
+func NewHTTPClient(opts ...HTTPOption) *http.Client {
+	defaults := []HTTPOption{
+		WithClientTimeout(DefaultHTTPClientTimeout),
+		WithSpanNameFormatter(clientSpanName),
+	}
+	c := newHTTPConfig(append(defaults, opts...))
+
+	return &http.Client{
+		Transport: c.transport(c.client.base),
+		Timeout:   c.client.timeout,
+	}
+}
+
+func clientSpanName(_ string, r *http.Request) string {
+	return r.Method + " " + r.URL.Host
+}
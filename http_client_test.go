@@ -0,0 +1,67 @@
+package trace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	base := &countingTransport{}
+	client := trace.NewHTTPClient(trace.WithBaseTransport(base))
+
+	if client.Timeout != trace.DefaultHTTPClientTimeout {
+		t.Errorf("expected timeout %v, got %v", trace.DefaultHTTPClientTimeout, client.Timeout)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if base.requests != 1 {
+		t.Errorf("expected the base transport to be used once, got %d", base.requests)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	u, _ := url.Parse(server.URL)
+	if want := "GET " + u.Host; spans[0].Name() != want {
+		t.Errorf("expected span name %q, got %q", want, spans[0].Name())
+	}
+}
+
+func TestNewHTTPClientOptions(t *testing.T) {
+	t.Parallel()
+
+	client := trace.NewHTTPClient(trace.WithClientTimeout(time.Second))
+
+	if client.Timeout != time.Second {
+		t.Errorf("expected timeout %v, got %v", time.Second, client.Timeout)
+	}
+	if client.Transport == nil {
+		t.Error("expected a traced transport")
+	}
+}
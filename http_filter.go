@@ -0,0 +1,123 @@
+package trace
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// HTTPFilter matches requests which are not traced, such as health checks,
+// metrics scrapes or static assets. A request matches the filter when it
+// matches any of its criteria.
+type HTTPFilter struct {
+	// PathPrefixes match the request path by prefix, such as "/healthz".
+	PathPrefixes []string
+	// PathGlobs match the request path with `path.Match` patterns, such as
+	// "/static/*.css".
+	PathGlobs []string
+	// Methods match the request method, such as "OPTIONS".
+	Methods []string
+	// UserAgents match the request user agent by case-insensitive substring,
+	// such as "kube-probe".
+	UserAgents []string
+	// SampleRate is the fraction of matched requests which are traced
+	// nonetheless, between 0 and 1.
+	SampleRate float64
+}
+
+//nolint:gochecknoglobals // Filter shared by all the HTTP handlers.
+var defaultHTTPFilter atomic.Pointer[HTTPFilter]
+
+// SetDefaultHTTPFilter configures a filter applied by all the HTTP handlers in
+// addition to the ones given with `WithHTTPFilter`. `NewProvider` sets it from
+// the `WithHTTPExcludedPaths` option. A nil filter removes it.
+func SetDefaultHTTPFilter(filter *HTTPFilter) {
+	defaultHTTPFilter.Store(filter)
+}
+
+type filterConfig struct {
+	filters []HTTPFilter
+}
+
+// WithHTTPFilter configures handlers not to trace the requests matched by the
+// filter. The option may be given several times, a request is then checked
+// against the first filter it matches.
+func WithHTTPFilter(filter HTTPFilter) HTTPOption {
+	return func(c *httpConfig) {
+		c.filter.filters = append(c.filter.filters, filter)
+	}
+}
+
+// Match reports whether the request matches any of the filter criteria.
+func (f *HTTPFilter) Match(r *http.Request) bool {
+	for _, prefix := range f.PathPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+
+	for _, pattern := range f.PathGlobs {
+		if ok, _ := path.Match(pattern, r.URL.Path); ok {
+			return true
+		}
+	}
+
+	for _, method := range f.Methods {
+		if strings.EqualFold(r.Method, method) {
+			return true
+		}
+	}
+
+	ua := strings.ToLower(r.UserAgent())
+	for _, agent := range f.UserAgents {
+		if strings.Contains(ua, strings.ToLower(agent)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sampled reports whether a matched request is traced nonetheless.
+func (f *HTTPFilter) sampled() bool {
+	return f.SampleRate > 0 && rand.Float64() < f.SampleRate //nolint:gosec // Sampling needs no secure source.
+}
+
+func (c filterConfig) handlerOptions() []otelhttp.Option {
+	return []otelhttp.Option{otelhttp.WithFilter(c.traced)}
+}
+
+// traced reports whether the request is traced, according to the first filter
+// it matches.
+func (c filterConfig) traced(r *http.Request) bool {
+	for i := range c.filters {
+		if c.filters[i].Match(r) {
+			return c.filters[i].sampled()
+		}
+	}
+
+	if f := defaultHTTPFilter.Load(); f != nil && f.Match(r) {
+		return f.sampled()
+	}
+
+	return true
+}
+
+// httpPathFilter returns a filter matching paths, which are globs when they
+// contain any of the `path.Match` special characters and prefixes otherwise.
+func httpPathFilter(paths []string, sampleRate float64) *HTTPFilter {
+	f := &HTTPFilter{SampleRate: sampleRate}
+	for _, p := range paths {
+		if strings.ContainsAny(p, `*?[\`) {
+			f.PathGlobs = append(f.PathGlobs, p)
+		} else {
+			f.PathPrefixes = append(f.PathPrefixes, p)
+		}
+	}
+
+	return f
+}
@@ -0,0 +1,129 @@
+package trace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestHTTPFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	filter := trace.HTTPFilter{
+		PathPrefixes: []string{"/healthz", "/readyz"},
+		PathGlobs:    []string{"/static/*.css"},
+		Methods:      []string{http.MethodOptions},
+		UserAgents:   []string{"kube-probe"},
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		target    string
+		userAgent string
+		match     bool
+	}{
+		{name: "matches path prefix", method: http.MethodGet, target: "/healthz/live", match: true},
+		{name: "matches path glob", method: http.MethodGet, target: "/static/app.css", match: true},
+		{name: "does not match other glob", method: http.MethodGet, target: "/static/app.js"},
+		{name: "matches method", method: http.MethodOptions, target: "/users", match: true},
+		{
+			name:      "matches user agent",
+			method:    http.MethodGet,
+			target:    "/users",
+			userAgent: "Kube-Probe/1.31",
+			match:     true,
+		},
+		{name: "does not match other requests", method: http.MethodGet, target: "/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+
+			if got := filter.Match(req); got != tt.match {
+				t.Errorf("expected Match=%v, got %v", tt.match, got)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []trace.HTTPOption
+		target string
+		traced bool
+	}{
+		{
+			name:   "traces requests by default",
+			target: "/healthz",
+			traced: true,
+		},
+		{
+			name:   "does not trace matched requests",
+			opts:   []trace.HTTPOption{trace.WithHTTPFilter(trace.HTTPFilter{PathPrefixes: []string{"/healthz"}})},
+			target: "/healthz",
+		},
+		{
+			name:   "traces unmatched requests",
+			opts:   []trace.HTTPOption{trace.WithHTTPFilter(trace.HTTPFilter{PathPrefixes: []string{"/healthz"}})},
+			target: "/users",
+			traced: true,
+		},
+		{
+			name: "samples matched requests",
+			opts: []trace.HTTPOption{
+				trace.WithHTTPFilter(trace.HTTPFilter{PathPrefixes: []string{"/healthz"}, SampleRate: 1}),
+			},
+			target: "/healthz",
+			traced: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			handler := trace.HTTPHandler(http.NotFoundHandler(), "operation", tt.opts...)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if traced := len(sr.Ended()) > 0; traced != tt.traced {
+				t.Errorf("expected traced=%v, got %v", tt.traced, traced)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerExcludedPaths(t *testing.T) {
+	t.Setenv("OTEL_TRACE_HTTP_EXCLUDED_PATHS", "/metrics,/static/*")
+	t.Cleanup(func() { trace.SetDefaultHTTPFilter(nil) })
+
+	provider, err := trace.NewProvider(
+		trace.WithTraceEnabled(true),
+		trace.WithTraceExporter(tracetest.NewInMemoryExporter()),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Shutdown() })
+
+	sr := setupHTTPTestTracer(t)
+
+	handler := trace.HTTPHandler(http.NotFoundHandler(), "operation")
+	for _, target := range []string{"/metrics", "/static/app.js", "/users"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+}
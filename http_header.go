@@ -0,0 +1,100 @@
+package trace
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type headerConfig struct {
+	request  []string
+	response []string
+}
+
+// WithCapturedRequestHeaders configures the request headers recorded on the
+// spans as "http.request.header.<name>" attributes, such as "X-Request-Id".
+// Credential headers such as "Authorization" or "Cookie" are never recorded.
+func WithCapturedRequestHeaders(names ...string) HTTPOption {
+	return func(c *httpConfig) {
+		c.header.request = appendCapturedHeaders(c.header.request, names)
+	}
+}
+
+// WithCapturedResponseHeaders configures the response headers recorded on the
+// spans as "http.response.header.<name>" attributes, such as "Cache-Control".
+// Credential headers such as "Set-Cookie" are never recorded.
+func WithCapturedResponseHeaders(names ...string) HTTPOption {
+	return func(c *httpConfig) {
+		c.header.response = appendCapturedHeaders(c.header.response, names)
+	}
+}
+
+// appendCapturedHeaders appends the canonical names of the headers which are
+// not denied.
+func appendCapturedHeaders(headers, names []string) []string {
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if !deniedHeader(name) {
+			headers = append(headers, name)
+		}
+	}
+	return headers
+}
+
+// deniedHeader reports whether a canonical header name carries credentials,
+// which must never be recorded.
+func deniedHeader(name string) bool {
+	switch name {
+	case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+		return true
+	default:
+		return false
+	}
+}
+
+// wrap records the captured headers on the span of the request.
+func (c headerConfig) wrap(handler http.Handler) http.Handler {
+	if len(c.request) == 0 && len(c.response) == 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(headerAttributes("http.request.header.", c.request, r.Header)...)
+
+		handler.ServeHTTP(w, r)
+
+		span.SetAttributes(headerAttributes("http.response.header.", c.response, w.Header())...)
+	})
+}
+
+// wrapTransport records the captured headers on the span of the request.
+func (c headerConfig) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if len(c.request) == 0 && len(c.response) == 0 {
+		return rt
+	}
+
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(headerAttributes("http.request.header.", c.request, r.Header)...)
+
+		resp, err := rt.RoundTrip(r)
+		if err == nil {
+			span.SetAttributes(headerAttributes("http.response.header.", c.response, resp.Header)...)
+		}
+
+		return resp, err
+	})
+}
+
+func headerAttributes(prefix string, names []string, header http.Header) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(names))
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			attrs = append(attrs, attribute.StringSlice(prefix+strings.ToLower(name), values))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,96 @@
+package trace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func assertHeaderAttribute(t *testing.T, span sdktrace.ReadOnlySpan, key attribute.Key, want []string) {
+	t.Helper()
+
+	value, ok := spanAttribute(span, key)
+	switch {
+	case want == nil && ok:
+		t.Errorf("expected no %s attribute, got %v", key, value.Emit())
+	case want != nil && !slices.Equal(value.AsStringSlice(), want):
+		t.Errorf("expected %s=%v, got %v", key, want, value.AsStringSlice())
+	}
+}
+
+func TestHTTPHandlerCapturedHeaders(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	handler := trace.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Set-Cookie", "session=secret")
+	}), "operation",
+		trace.WithCapturedRequestHeaders("x-request-id", "X-Tenant", "Authorization", "cookie"),
+		trace.WithCapturedResponseHeaders("Cache-Control", "Set-Cookie"),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Id", "42")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	assertHeaderAttribute(t, spans[0], "http.request.header.x-request-id", []string{"42"})
+	assertHeaderAttribute(t, spans[0], "http.request.header.x-tenant", nil)
+	assertHeaderAttribute(t, spans[0], "http.request.header.authorization", nil)
+	assertHeaderAttribute(t, spans[0], "http.request.header.cookie", nil)
+	assertHeaderAttribute(t, spans[0], "http.response.header.cache-control", []string{"no-store"})
+	assertHeaderAttribute(t, spans[0], "http.response.header.set-cookie", nil)
+}
+
+func TestHTTPClientTransporterCapturedHeaders(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Set("Set-Cookie", "session=secret")
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: trace.HTTPClientTransporter(nil,
+			trace.WithCapturedRequestHeaders("X-Request-Id", "Proxy-Authorization"),
+			trace.WithCapturedResponseHeaders("Vary", "Set-Cookie"),
+		),
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("X-Request-Id", "42")
+	req.Header.Set("Proxy-Authorization", "Basic secret")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	assertHeaderAttribute(t, spans[0], "http.request.header.x-request-id", []string{"42"})
+	assertHeaderAttribute(t, spans[0], "http.request.header.proxy-authorization", nil)
+	assertHeaderAttribute(t, spans[0], "http.response.header.vary", []string{"Accept", "Accept-Encoding"})
+	assertHeaderAttribute(t, spans[0], "http.response.header.set-cookie", nil)
+}
@@ -0,0 +1,96 @@
+package trace
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recoverConfig struct {
+	enabled bool
+	repanic bool
+}
+
+// WithPanicRecovery configures handlers to recover panics, which are recorded
+// against the span with `RecordError` as a `PanicError` along with their stack
+// trace. A "500 Internal Server Error" response is written unless the handler
+// already wrote the response headers. When `repanic` is true, the panic is
+// raised again afterwards so that recovery middlewares up the chain still run.
+// Panics with `http.ErrAbortHandler`, which abort the response on purpose, are
+// never recovered.
+func WithPanicRecovery(repanic bool) HTTPOption {
+	return func(c *httpConfig) {
+		c.panics.enabled = true
+		c.panics.repanic = repanic
+	}
+}
+
+// wrap recovers the panics of the handler.
+func (c recoverConfig) wrap(handler http.Handler) http.Handler {
+	if !c.enabled {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		written := false
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+
+			RecordError(trace.SpanFromContext(r.Context()), &PanicError{Value: v, Stack: debug.Stack()})
+			if !written {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+
+			if c.repanic {
+				panic(v)
+			}
+		}()
+
+		handler.ServeHTTP(httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					if code >= http.StatusOK {
+						written = true
+					}
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(p []byte) (int, error) {
+					written = true
+					return next(p)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					written = true
+					return next(src)
+				}
+			},
+			Flush: func(next httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+				return func() {
+					written = true
+					next()
+				}
+			},
+			Hijack: func(next httpsnoop.HijackFunc) httpsnoop.HijackFunc {
+				return func() (net.Conn, *bufio.ReadWriter, error) {
+					written = true
+					return next()
+				}
+			},
+		}), r)
+	})
+}
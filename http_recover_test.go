@@ -0,0 +1,106 @@
+package trace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestHTTPHandlerPanicRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+	}{
+		{
+			name:    "writes a 500 response",
+			handler: func(http.ResponseWriter, *http.Request) { panic("boom") },
+			status:  http.StatusInternalServerError,
+		},
+		{
+			name: "keeps the response written before the panic",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("boom")
+			},
+			status: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			rec := httptest.NewRecorder()
+			handler := trace.HTTPHandler(tt.handler, "operation", trace.WithPanicRecovery(false))
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status code %d, got %d", tt.status, rec.Code)
+			}
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			if spans[0].Status().Code != codes.Error {
+				t.Errorf("expected error status, got %v", spans[0].Status())
+			}
+
+			events := spans[0].Events()
+			if len(events) != 1 || events[0].Name != semconv.ExceptionEventName {
+				t.Fatalf("expected an exception event, got %v", events)
+			}
+			if stack, ok := eventAttribute(events[0], semconv.ExceptionStacktraceKey); !ok ||
+				!strings.Contains(stack, "panic") {
+				t.Errorf("expected a stack trace, got %q", stack)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerPanicRecoveryRepanic(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	rec := httptest.NewRecorder()
+	handler := trace.HTTPHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}), "operation", trace.WithPanicRecovery(true))
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected panic %q, got %v", "boom", r)
+			}
+		}()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if spans := sr.Ended(); len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Errorf("expected 1 failed span, got %v", spans)
+	}
+}
+
+func TestHTTPHandlerPanicRecoveryAbort(t *testing.T) {
+	setupHTTPTestTracer(t)
+
+	handler := trace.HTTPHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), "operation", trace.WithPanicRecovery(false))
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler { //nolint:errorlint // The panic value is compared as is.
+			t.Errorf("expected panic %v, got %v", http.ErrAbortHandler, r)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,122 @@
+package trace
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type responseConfig struct {
+	traceIDHeader string
+	traceResponse bool
+	serverTiming  bool
+}
+
+// WithTraceIDHeader configures handlers to write the trace ID of the request
+// into the named response header, such as "X-Trace-Id", so that it can be
+// reported by clients.
+func WithTraceIDHeader(name string) HTTPOption {
+	return func(c *httpConfig) {
+		c.response.traceIDHeader = name
+	}
+}
+
+// WithTraceResponse configures handlers to write the W3C "traceresponse"
+// response header, which holds the trace and span IDs of the server span.
+func WithTraceResponse() HTTPOption {
+	return func(c *httpConfig) {
+		c.response.traceResponse = true
+	}
+}
+
+// WithServerTiming configures handlers to add an "app" metric to the
+// "Server-Timing" response header, whose duration is the time spent serving
+// the request until the response headers were written.
+func WithServerTiming() HTTPOption {
+	return func(c *httpConfig) {
+		c.response.serverTiming = true
+	}
+}
+
+func (c responseConfig) enabled() bool {
+	return len(c.traceIDHeader) > 0 || c.traceResponse || c.serverTiming
+}
+
+// wrap writes the configured response headers before the handler flushes
+// them.
+func (c responseConfig) wrap(handler http.Handler) http.Handler {
+	if !c.enabled() {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sc := trace.SpanContextFromContext(r.Context())
+		if !sc.IsValid() {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		written := false
+		writeHeaders := func() {
+			if written {
+				return
+			}
+			written = true
+			c.setHeaders(header, sc, time.Since(start))
+		}
+
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					// Informational responses do not flush the final headers.
+					if code >= http.StatusOK {
+						writeHeaders()
+					}
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(p []byte) (int, error) {
+					writeHeaders()
+					return next(p)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					writeHeaders()
+					return next(src)
+				}
+			},
+			Flush: func(next httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+				return func() {
+					writeHeaders()
+					next()
+				}
+			},
+		})
+
+		handler.ServeHTTP(w, r)
+		writeHeaders()
+	})
+}
+
+func (c responseConfig) setHeaders(header http.Header, sc trace.SpanContext, elapsed time.Duration) {
+	if len(c.traceIDHeader) > 0 {
+		header.Set(c.traceIDHeader, sc.TraceID().String())
+	}
+
+	if c.traceResponse {
+		header.Set("traceresponse", "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-"+sc.TraceFlags().String())
+	}
+
+	if c.serverTiming {
+		ms := float64(elapsed) / float64(time.Millisecond)
+		header.Add("Server-Timing", "app;dur="+strconv.FormatFloat(ms, 'f', 3, 64))
+	}
+}
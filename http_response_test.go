@@ -0,0 +1,95 @@
+package trace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestHTTPHandlerResponseHeaders(t *testing.T) {
+	opts := []trace.HTTPOption{
+		trace.WithTraceIDHeader("X-Trace-Id"),
+		trace.WithTraceResponse(),
+		trace.WithServerTiming(),
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name:    "writes headers when the handler writes nothing",
+			handler: func(http.ResponseWriter, *http.Request) {},
+		},
+		{
+			name: "writes headers before the status code",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			},
+		},
+		{
+			name: "writes headers before the body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("OK"))
+			},
+		},
+		{
+			name: "writes headers before flushing",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.(http.Flusher).Flush()
+				w.Header().Set("X-Trace-Id", "too late")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			server := httptest.NewServer(trace.HTTPHandler(tt.handler, "operation", opts...))
+			defer server.Close()
+
+			resp, err := server.Client().Get(server.URL)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			server.Close()
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			sc := spans[0].SpanContext()
+
+			if got := resp.Header.Get("X-Trace-Id"); got != sc.TraceID().String() {
+				t.Errorf("expected X-Trace-Id=%q, got %q", sc.TraceID(), got)
+			}
+
+			want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"
+			if got := resp.Header.Get("traceresponse"); got != want {
+				t.Errorf("expected traceresponse=%q, got %q", want, got)
+			}
+
+			if got := resp.Header.Get("Server-Timing"); !regexp.MustCompile(`^app;dur=\d+\.\d{3}$`).MatchString(got) {
+				t.Errorf("unexpected Server-Timing %q", got)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerResponseHeadersDisabled(t *testing.T) {
+	setupHTTPTestTracer(t)
+
+	rec := httptest.NewRecorder()
+	trace.HTTPHandler(http.NotFoundHandler(), "operation").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	for _, name := range []string{"traceresponse", "Server-Timing"} {
+		if got := rec.Header().Get(name); len(got) > 0 {
+			t.Errorf("expected no %s header, got %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package trace
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type routeConfig struct {
+	enabled   bool
+	formatter func(*http.Request) string
+}
+
+// WithRouteFormatter configures the function returning the route of a request
+// which was not routed by `http.ServeMux`, such as "/users/{id}", and enables
+// route-aware span names, see `HTTPRouteHandler`. It is called once the request
+// was served and must not return raw paths, which have a high cardinality.
+func WithRouteFormatter(fn func(*http.Request) string) HTTPOption {
+	return func(c *httpConfig) {
+		c.route.enabled = true
+		c.route.formatter = fn
+	}
+}
+
+// HTTPRouteHandler is a convenience function which helps attaching tracing
+// functionality to routers such as `http.ServeMux`. Spans are named after the
+// method and the pattern which matched the request, "GET /users/{id}" for
+// instance, which is also recorded as the "http.route" attribute. Requests
+// which matched no pattern, and for which `WithRouteFormatter` returned no
+// route, are named after the method only.
+func HTTPRouteHandler(handler http.Handler, opts ...HTTPOption) http.Handler {
+	c := newHTTPConfig(opts)
+	c.route.enabled = true
+
+	return c.handler(handler, "")
+}
+
+func (c routeConfig) handlerOptions() []otelhttp.Option {
+	if !c.enabled {
+		return nil
+	}
+
+	// The formatter is called before and, when a pattern matched, after the
+	// request is routed.
+	return []otelhttp.Option{
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return routeSpanName(r.Method, patternRoute(r.Pattern))
+		}),
+	}
+}
+
+// wrap names the span after the route once the request was served.
+func (c routeConfig) wrap(handler http.Handler) http.Handler {
+	if !c.enabled {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+
+		route := patternRoute(r.Pattern)
+		if len(route) == 0 && c.formatter != nil {
+			route = c.formatter(r)
+		}
+		if len(route) == 0 {
+			return
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(routeSpanName(r.Method, route))
+		span.SetAttributes(semconv.HTTPRouteKey.String(route))
+	})
+}
+
+func routeSpanName(method, route string) string {
+	if len(route) == 0 {
+		return method
+	}
+	return method + " " + route
+}
+
+// patternRoute returns the path of a `http.ServeMux` pattern, which is of the
+// form "[METHOD ][HOST]/[PATH]".
+func patternRoute(pattern string) string {
+	if _, rest, found := strings.Cut(pattern, " "); found {
+		pattern = strings.TrimLeft(rest, " \t")
+	}
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		return pattern[i:]
+	}
+	return ""
+}
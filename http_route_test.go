@@ -0,0 +1,106 @@
+package trace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestHTTPRouteHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc("example.com/static/", func(http.ResponseWriter, *http.Request) {})
+
+	tests := []struct {
+		name     string
+		handler  func() http.Handler
+		target   string
+		spanName string
+		route    string
+	}{
+		{
+			name:     "names span after method and pattern",
+			handler:  func() http.Handler { return trace.HTTPRouteHandler(mux) },
+			target:   "/users/42",
+			spanName: "GET /users/{id}",
+			route:    "/users/{id}",
+		},
+		{
+			name:     "strips host from pattern",
+			handler:  func() http.Handler { return trace.HTTPRouteHandler(mux) },
+			target:   "http://example.com/static/app.js",
+			spanName: "GET /static/",
+			route:    "/static/",
+		},
+		{
+			name:     "names unmatched request after method",
+			handler:  func() http.Handler { return trace.HTTPRouteHandler(mux) },
+			target:   "/unknown/42",
+			spanName: "GET",
+		},
+		{
+			name: "falls back to route formatter",
+			handler: func() http.Handler {
+				return trace.HTTPRouteHandler(
+					http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+					trace.WithRouteFormatter(func(*http.Request) string { return "/orders/:id" }),
+				)
+			},
+			target:   "/orders/42",
+			spanName: "GET /orders/:id",
+			route:    "/orders/:id",
+		},
+		{
+			name: "enables route names on HTTPHandler",
+			handler: func() http.Handler {
+				return trace.HTTPHandler(
+					http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+					"orders",
+					trace.WithRouteFormatter(func(*http.Request) string { return "/orders/:id" }),
+				)
+			},
+			target:   "/orders/42",
+			spanName: "GET /orders/:id",
+			route:    "/orders/:id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			tt.handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			if spans[0].Name() != tt.spanName {
+				t.Errorf("expected span name %q, got %q", tt.spanName, spans[0].Name())
+			}
+
+			route, ok := spanAttribute(spans[0], "http.route")
+			if len(tt.route) == 0 {
+				if ok {
+					t.Errorf("expected no http.route attribute, got %q", route.AsString())
+				}
+			} else if route.AsString() != tt.route {
+				t.Errorf("expected http.route %q, got %q", tt.route, route.AsString())
+			}
+		})
+	}
+}
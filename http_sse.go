@@ -0,0 +1,104 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSSEEvent is returned when the ID or the name of a Server-Sent
+// Event holds a line break.
+var ErrInvalidSSEEvent = errors.New("invalid server-sent event")
+
+// SSEEvent is an event of a Server-Sent Events stream. Only `Data` is
+// required, it may span several lines.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// SSEWriter writes a Server-Sent Events stream, traced as a `Stream` whose
+// messages are the events sent.
+type SSEWriter struct {
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	stream *Stream
+}
+
+// NewSSEWriter writes the headers of a Server-Sent Events stream and starts its
+// connection span, named after the route of the request, "SSE /events" for
+// instance. An error is returned when the response cannot be flushed.
+// `SSEWriter.Close` must be called once the stream is over.
+func NewSSEWriter(w http.ResponseWriter, r *http.Request, opts ...StreamOption) (*SSEWriter, error) {
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return nil, fmt.Errorf("flushing server-sent events stream: %w", err)
+	}
+
+	_, stream := StartStream(r.Context(), routeSpanName("SSE", patternRoute(r.Pattern)), opts...)
+
+	return &SSEWriter{
+		w:      w,
+		rc:     rc,
+		stream: stream,
+	}, nil
+}
+
+// Context returns the context holding the connection span.
+func (s *SSEWriter) Context() context.Context {
+	return s.stream.Context()
+}
+
+// Send writes and flushes an event.
+func (s *SSEWriter) Send(event SSEEvent) error {
+	if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Event, "\r\n") {
+		return fmt.Errorf("%w: line break in ID or event name", ErrInvalidSSEEvent)
+	}
+
+	var b strings.Builder
+	if len(event.ID) > 0 {
+		b.WriteString("id: " + event.ID + "\n")
+	}
+	if len(event.Event) > 0 {
+		b.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	// A lone "\r" also ends a line, normalise it so that it cannot inject fields.
+	data := strings.ReplaceAll(strings.ReplaceAll(event.Data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	_, span := s.stream.Message(MessageSent, b.Len())
+	defer span.End()
+
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		RecordError(span, err)
+		return fmt.Errorf("writing server-sent event: %w", err)
+	}
+	if err := s.rc.Flush(); err != nil {
+		RecordError(span, err)
+		return fmt.Errorf("flushing server-sent event: %w", err)
+	}
+
+	return nil
+}
+
+// Close ends the connection span, see `Stream.End`.
+func (s *SSEWriter) Close(err error) {
+	s.stream.End(err)
+}
@@ -0,0 +1,61 @@
+package trace_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestSSEWriter(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		sse, err := trace.NewSSEWriter(w, r)
+		if err != nil {
+			t.Errorf("NewSSEWriter failed: %v", err)
+			return
+		}
+		defer sse.Close(nil)
+
+		if err := sse.Send(trace.SSEEvent{ID: "1", Event: "update", Data: "a\nb", Retry: time.Second}); err != nil {
+			t.Errorf("Send failed: %v", err)
+		}
+		if err := sse.Send(trace.SSEEvent{Data: "x\revent: evil\r\ndata: injected"}); err != nil {
+			t.Errorf("Send failed: %v", err)
+		}
+		if err := sse.Send(trace.SSEEvent{Event: "bad\nname"}); !errors.Is(err, trace.ErrInvalidSSEEvent) {
+			t.Errorf("expected ErrInvalidSSEEvent, got %v", err)
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	trace.HTTPRouteHandler(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type %q, got %q", "text/event-stream", ct)
+	}
+	want := "id: 1\nevent: update\nretry: 1000\ndata: a\ndata: b\n\n" +
+		"data: x\ndata: event: evil\ndata: data: injected\n\n"
+	if rec.Body.String() != want {
+		t.Errorf("expected body %q, got %q", want, rec.Body.String())
+	}
+
+	var stream bool
+	for _, span := range sr.Ended() {
+		if span.Name() != "SSE /events" {
+			continue
+		}
+		stream = true
+		if len(span.Events()) != 2 {
+			t.Errorf("expected 2 message events, got %d", len(span.Events()))
+		}
+	}
+	if !stream {
+		t.Error("expected a SSE /events span")
+	}
+}
@@ -3,8 +3,13 @@ package trace_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
 	"go.pixelfactory.io/pkg/observability/trace"
 )
 
@@ -168,3 +173,145 @@ func TestHTTPClientTransporter(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTPHandlerOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []trace.HTTPOption
+		spans []string
+	}{
+		{
+			name:  "names spans after the operation by default",
+			spans: []string{"operation"},
+		},
+		{
+			name: "filters requests",
+			opts: []trace.HTTPOption{
+				trace.WithRequestFilter(func(r *http.Request) bool { return r.URL.Path != "/healthz" }),
+			},
+		},
+		{
+			name: "formats span names",
+			opts: []trace.HTTPOption{
+				trace.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+					return operation + " " + r.URL.Path
+				}),
+			},
+			spans: []string{"operation /healthz"},
+		},
+		{
+			name: "passes otelhttp options",
+			opts: []trace.HTTPOption{
+				trace.WithOtelHTTPOptions(otelhttp.WithSpanNameFormatter(func(string, *http.Request) string {
+					return "custom"
+				})),
+			},
+			spans: []string{"custom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			handler := trace.HTTPHandler(http.NotFoundHandler(), "operation", tt.opts...)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			var names []string
+			for _, span := range sr.Ended() {
+				names = append(names, span.Name())
+			}
+			if !slices.Equal(names, tt.spans) {
+				t.Errorf("expected spans %v, got %v", tt.spans, names)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerPublicEndpoint(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	handler := trace.HTTPHandler(http.NotFoundHandler(), "operation", trace.WithPublicEndpoint())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", remoteTraceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].SpanContext().TraceID().String() == remoteTraceID {
+		t.Error("expected a new trace")
+	}
+	if len(spans[0].Links()) != 1 || spans[0].Links()[0].SpanContext.TraceID().String() != remoteTraceID {
+		t.Error("expected a link to the remote span")
+	}
+}
+
+func TestHTTPHandlerMessageEvents(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	handler := trace.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}), "operation", trace.WithMessageEvents(false, true))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if events := spans[0].Events(); len(events) != 1 || events[0].Name != "write" {
+		t.Errorf("expected a write event, got %v", events)
+	}
+}
+
+func TestHTTPClientTransporterProvider(t *testing.T) {
+	// The transport is created before the provider is configured.
+	client := &http.Client{
+		Transport: trace.HTTPClientTransporter(http.DefaultTransport),
+	}
+
+	sr := setupHTTPTestTracer(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].SpanKind() != oteltrace.SpanKindClient {
+		t.Errorf("expected a client span, got %v", spans[0].SpanKind())
+	}
+	if !strings.Contains(traceparent, spans[0].SpanContext().SpanID().String()) {
+		t.Errorf("expected traceparent to carry the client span, got %q", traceparent)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(http.ResponseWriter, *http.Request) {})
+
+	handler := trace.Middleware()(mux)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name() != "GET /users/{id}" {
+		t.Errorf("expected span name %q, got %q", "GET /users/{id}", spans[0].Name())
+	}
+}
@@ -0,0 +1,174 @@
+package trace
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClientTiming decides how the phases of client requests are recorded, see
+// `WithClientTiming`.
+type ClientTiming int
+
+const (
+	// ClientTimingEvents records each phase as an event of the client span,
+	// along with its duration.
+	ClientTimingEvents ClientTiming = iota + 1
+	// ClientTimingSpans records each phase as a child span of the client span.
+	ClientTimingSpans
+)
+
+type timingConfig struct {
+	mode ClientTiming
+}
+
+// WithClientTiming configures transports to record the phases of requests:
+// acquiring a connection ("http.getconn", along with whether it was reused),
+// resolving the host ("http.dns"), connecting ("http.connect"), the TLS
+// handshake ("http.tls"), sending the request ("http.send") and waiting for the
+// first byte of the response ("http.wait").
+func WithClientTiming(mode ClientTiming) HTTPOption {
+	return func(c *httpConfig) {
+		c.timing.mode = mode
+	}
+}
+
+// wrapTransport attaches a client trace recording the phases of the request
+// to the span of the request.
+func (c timingConfig) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if c.mode == 0 {
+		return rt
+	}
+
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		span := trace.SpanFromContext(r.Context())
+		if !span.IsRecording() {
+			return rt.RoundTrip(r)
+		}
+
+		t := &clientTimer{
+			mode:   c.mode,
+			ctx:    r.Context(),
+			span:   span,
+			phases: map[string]clientPhase{},
+		}
+
+		return rt.RoundTrip(r.WithContext(httptrace.WithClientTrace(r.Context(), t.clientTrace())))
+	})
+}
+
+type clientPhase struct {
+	start time.Time
+	attrs []attribute.KeyValue
+}
+
+// clientTimer records the phases of a request. Hooks may be called from other
+// goroutines, such as the one dialing the connection.
+type clientTimer struct {
+	mode ClientTiming
+	ctx  context.Context
+	span trace.Span
+
+	mu     sync.Mutex
+	phases map[string]clientPhase
+}
+
+func (t *clientTimer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.start("http.getconn", attribute.String("http.conn.host", hostPort))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			attrs := []attribute.KeyValue{
+				attribute.Bool("http.conn.reused", info.Reused),
+				attribute.Bool("http.conn.was_idle", info.WasIdle),
+			}
+			if info.WasIdle {
+				attrs = append(attrs, attribute.String("http.conn.idle_time", info.IdleTime.String()))
+			}
+			t.end("http.getconn", nil, attrs...)
+			t.start("http.send")
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			t.start("http.dns", semconv.NetPeerNameKey.String(info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.end("http.dns", info.Err)
+		},
+		ConnectStart: func(network, addr string) {
+			// Several addresses may be dialed concurrently.
+			t.start("http.connect "+addr,
+				attribute.String("http.conn.network", network),
+				attribute.String("http.conn.addr", addr),
+			)
+		},
+		ConnectDone: func(_, addr string, err error) {
+			t.end("http.connect "+addr, err)
+		},
+		TLSHandshakeStart: func() {
+			t.start("http.tls")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			t.end("http.tls", err, attribute.Bool("http.tls.resumed", state.DidResume))
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			t.end("http.send", info.Err)
+			t.start("http.wait")
+		},
+		GotFirstResponseByte: func() {
+			t.end("http.wait", nil)
+		},
+	}
+}
+
+func (t *clientTimer) start(key string, attrs ...attribute.KeyValue) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases[key] = clientPhase{start: time.Now(), attrs: attrs}
+}
+
+// end records the phase identified by key, whose name is the first word of
+// the key.
+func (t *clientTimer) end(key string, err error, attrs ...attribute.KeyValue) {
+	end := time.Now()
+
+	t.mu.Lock()
+	phase, ok := t.phases[key]
+	delete(t.phases, key)
+	t.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	name, _, _ := strings.Cut(key, " ")
+	phase.attrs = append(phase.attrs, attrs...)
+
+	switch t.mode {
+	case ClientTimingSpans:
+		_, span := DefaultTracer().Tracer().Start(t.ctx, name,
+			trace.WithTimestamp(phase.start),
+			trace.WithAttributes(phase.attrs...),
+		)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End(trace.WithTimestamp(end))
+	default:
+		ms := float64(end.Sub(phase.start)) / float64(time.Millisecond)
+		phase.attrs = append(phase.attrs, attribute.Float64("http.phase.duration_ms", ms))
+		if err != nil {
+			phase.attrs = append(phase.attrs, attribute.String("http.phase.error", err.Error()))
+		}
+		t.span.AddEvent(name, trace.WithTimestamp(end), trace.WithAttributes(phase.attrs...))
+	}
+}
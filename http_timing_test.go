@@ -0,0 +1,117 @@
+package trace_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func getAll(t *testing.T, client *http.Client, url string, n int) {
+	t.Helper()
+
+	for range n {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+func eventNames(span sdktrace.ReadOnlySpan) []string {
+	var names []string
+	for _, event := range span.Events() {
+		names = append(names, event.Name)
+	}
+	return names
+}
+
+func TestHTTPClientTransporterTimingEvents(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: trace.HTTPClientTransporter(server.Client().Transport,
+			trace.WithClientTiming(trace.ClientTimingEvents),
+		),
+	}
+	getAll(t, client, server.URL, 2)
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	first := eventNames(spans[0])
+	for _, name := range []string{"http.getconn", "http.connect", "http.tls", "http.send", "http.wait"} {
+		if !slices.Contains(first, name) {
+			t.Errorf("expected %s event on first request, got %v", name, first)
+		}
+	}
+
+	second := eventNames(spans[1])
+	if slices.Contains(second, "http.connect") {
+		t.Errorf("expected no http.connect event on second request, got %v", second)
+	}
+
+	for i, span := range spans {
+		for _, event := range span.Events() {
+			if event.Name != "http.getconn" {
+				continue
+			}
+			reused := slices.Contains(event.Attributes, attribute.Bool("http.conn.reused", true))
+			if reused != (i == 1) {
+				t.Errorf("request %d: expected http.conn.reused=%v", i, i == 1)
+			}
+		}
+	}
+}
+
+func TestHTTPClientTransporterTimingSpans(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: trace.HTTPClientTransporter(nil, trace.WithClientTiming(trace.ClientTimingSpans)),
+	}
+	getAll(t, client, server.URL, 1)
+
+	var parent sdktrace.ReadOnlySpan
+	children := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range sr.Ended() {
+		if span.Parent().IsValid() {
+			children[span.Name()] = span
+		} else {
+			parent = span
+		}
+	}
+	if parent == nil {
+		t.Fatal("expected a client span")
+	}
+
+	for _, name := range []string{"http.getconn", "http.connect", "http.send", "http.wait"} {
+		child, ok := children[name]
+		if !ok {
+			t.Errorf("expected %s span", name)
+			continue
+		}
+		if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected %s to be a child of the client span", name)
+		}
+		if child.EndTime().Before(child.StartTime()) {
+			t.Errorf("expected %s to end after it started", name)
+		}
+	}
+}
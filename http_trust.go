@@ -0,0 +1,136 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TrustPolicy decides how HTTP handlers treat the trace context of incoming
+// requests which are not trusted, see `WithTrustedNetworks` and
+// `WithTrustedRequests`.
+type TrustPolicy int
+
+const (
+	// TrustRemote continues the incoming trace context of every request. This
+	// is the default.
+	TrustRemote TrustPolicy = iota
+	// IgnoreRemote ignores the incoming trace context and baggage of untrusted
+	// requests, which start a new trace.
+	IgnoreRemote
+	// LinkRemote starts a new trace for untrusted requests, whose span links
+	// to the incoming trace context. Their baggage is ignored.
+	LinkRemote
+)
+
+type trustConfig struct {
+	policy   TrustPolicy
+	networks []netip.Prefix
+	requests []func(*http.Request) bool
+}
+
+// WithTrustPolicy configures how the trace context of untrusted incoming
+// requests is treated. Unless the policy is `TrustRemote`, requests are
+// untrusted unless they match `WithTrustedNetworks` or `WithTrustedRequests`.
+func WithTrustPolicy(policy TrustPolicy) HTTPOption {
+	return func(c *httpConfig) {
+		c.trust.policy = policy
+	}
+}
+
+// WithTrustedNetworks configures the networks whose requests are trusted, the
+// remote address of the connection being matched against them.
+func WithTrustedNetworks(prefixes ...netip.Prefix) HTTPOption {
+	return func(c *httpConfig) {
+		c.trust.networks = append(c.trust.networks, prefixes...)
+	}
+}
+
+// WithTrustedRequests configures a predicate of trusted requests, such as the
+// presence of a header set by an internal gateway.
+func WithTrustedRequests(fn func(*http.Request) bool) HTTPOption {
+	return func(c *httpConfig) {
+		c.trust.requests = append(c.trust.requests, fn)
+	}
+}
+
+type untrustedKey struct{}
+
+func (c trustConfig) handlerOptions() []otelhttp.Option {
+	if c.policy == TrustRemote {
+		return nil
+	}
+
+	return []otelhttp.Option{
+		otelhttp.WithPropagators(trustPropagator{}),
+		otelhttp.WithPublicEndpointFn(func(r *http.Request) bool {
+			return untrustedPolicy(r.Context()) == LinkRemote
+		}),
+	}
+}
+
+// wrap marks the context of untrusted requests with the policy before they
+// reach the instrumented handler.
+func (c trustConfig) wrap(handler http.Handler) http.Handler {
+	if c.policy == TrustRemote {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.trusted(r) {
+			r = r.WithContext(context.WithValue(r.Context(), untrustedKey{}, c.policy))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (c trustConfig) trusted(r *http.Request) bool {
+	if len(c.networks) > 0 {
+		if addr, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+			for _, prefix := range c.networks {
+				if prefix.Contains(addr.Addr().Unmap()) {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, fn := range c.requests {
+		if fn(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func untrustedPolicy(ctx context.Context) TrustPolicy {
+	policy, ok := ctx.Value(untrustedKey{}).(TrustPolicy)
+	if !ok {
+		return TrustRemote
+	}
+	return policy
+}
+
+// trustPropagator extracts nothing from untrusted requests which must be
+// ignored, only the span context to link to from untrusted requests which must
+// be linked, and delegates to the global propagator otherwise.
+type trustPropagator struct {
+	globalPropagator
+}
+
+func (p trustPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	switch untrustedPolicy(ctx) {
+	case IgnoreRemote:
+		return ctx
+	case LinkRemote:
+		sc := trace.SpanContextFromContext(p.globalPropagator.Extract(context.Background(), carrier))
+		return trace.ContextWithRemoteSpanContext(ctx, sc)
+	case TrustRemote:
+	}
+	return p.globalPropagator.Extract(ctx, carrier)
+}
@@ -0,0 +1,126 @@
+package trace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+const (
+	remoteTraceID     = "0af7651916cd43dd8448eb211c80319c"
+	remoteTraceparent = "00-" + remoteTraceID + "-b7ad6b7169203331-01"
+)
+
+// setupHTTPTestTracer configures a recording tracer provider and the W3C
+// propagators globally, as NewProvider does.
+func setupHTTPTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	sr, cleanup := setupTestTracer()
+	t.Cleanup(cleanup)
+
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	return sr
+}
+
+func TestHTTPHandlerTrustPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []trace.HTTPOption
+		header    http.Header
+		continued bool
+		linked    bool
+	}{
+		{
+			name:      "trusts remote by default",
+			continued: true,
+		},
+		{
+			name: "ignores untrusted remote",
+			opts: []trace.HTTPOption{trace.WithTrustPolicy(trace.IgnoreRemote)},
+		},
+		{
+			name:   "links untrusted remote",
+			opts:   []trace.HTTPOption{trace.WithTrustPolicy(trace.LinkRemote)},
+			linked: true,
+		},
+		{
+			name: "trusts remote from trusted network",
+			opts: []trace.HTTPOption{
+				trace.WithTrustPolicy(trace.IgnoreRemote),
+				trace.WithTrustedNetworks(netip.MustParsePrefix("192.0.2.0/24")),
+			},
+			continued: true,
+		},
+		{
+			name: "ignores remote from other network",
+			opts: []trace.HTTPOption{
+				trace.WithTrustPolicy(trace.IgnoreRemote),
+				trace.WithTrustedNetworks(netip.MustParsePrefix("10.0.0.0/8")),
+			},
+		},
+		{
+			name: "trusts remote matching predicate",
+			opts: []trace.HTTPOption{
+				trace.WithTrustPolicy(trace.LinkRemote),
+				trace.WithTrustedRequests(func(r *http.Request) bool {
+					return r.Header.Get("X-Internal") == "true"
+				}),
+			},
+			header:    http.Header{"X-Internal": {"true"}},
+			continued: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			var baggage string
+			handler := trace.HTTPHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				baggage = trace.GetBaggage(r.Context(), "tenant.id")
+			}), "trust-handler", tt.opts...)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("Traceparent", remoteTraceparent)
+			req.Header.Set("Baggage", "tenant.id=acme")
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			continued := spans[0].SpanContext().TraceID().String() == remoteTraceID
+			if continued != tt.continued {
+				t.Errorf("expected remote trace continued=%v, got %v", tt.continued, continued)
+			}
+
+			linked := len(spans[0].Links()) == 1 && spans[0].Links()[0].SpanContext.TraceID().String() == remoteTraceID
+			if linked != tt.linked {
+				t.Errorf("expected remote trace linked=%v, got %v", tt.linked, linked)
+			}
+
+			if tt.continued != (baggage != "") {
+				t.Errorf("expected remote baggage kept=%v, got baggage %q", tt.continued, baggage)
+			}
+		})
+	}
+}
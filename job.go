@@ -0,0 +1,163 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultJobFlushTimeout is the maximum time spent exporting the spans of a
+// job run once it is over.
+const DefaultJobFlushTimeout = 5 * time.Second
+
+// Outcomes of a job attempt, recorded as the "job.outcome" attribute.
+const (
+	JobOutcomeSuccess = "success"
+	JobOutcomeFailure = "failure"
+)
+
+// JobOption configures a `Job`.
+type JobOption func(*jobConfig)
+
+type jobConfig struct {
+	schedule     string
+	retries      int
+	retryDelay   time.Duration
+	flushTimeout time.Duration
+	attributes   []attribute.KeyValue
+}
+
+// WithJobSchedule records the schedule of the job, such as a cron expression
+// or an interval, as the "job.schedule" attribute.
+func WithJobSchedule(schedule string) JobOption {
+	return func(c *jobConfig) {
+		c.schedule = schedule
+	}
+}
+
+// WithJobRetries retries failed runs up to `retries` times, waiting `delay`
+// between attempts.
+func WithJobRetries(retries int, delay time.Duration) JobOption {
+	return func(c *jobConfig) {
+		c.retries = retries
+		c.retryDelay = delay
+	}
+}
+
+// WithJobFlushTimeout configures the maximum time spent exporting the spans of
+// a run once it is over. It is `DefaultJobFlushTimeout` by default, zero
+// disables the flush.
+func WithJobFlushTimeout(timeout time.Duration) JobOption {
+	return func(c *jobConfig) {
+		c.flushTimeout = timeout
+	}
+}
+
+// WithJobAttributes adds attributes to the span of every attempt.
+func WithJobAttributes(attrs ...attribute.KeyValue) JobOption {
+	return func(c *jobConfig) {
+		c.attributes = append(c.attributes, attrs...)
+	}
+}
+
+// Job traces the runs of a background or scheduled job. Each attempt of a run
+// is the root span of its own trace, named after the job, with the "job.name",
+// "job.schedule", "job.attempt" and "job.outcome" attributes. Retries are
+// linked to the first attempt of the run.
+type Job struct {
+	name string
+	cfg  jobConfig
+}
+
+// NewJob returns a new `Job`.
+func NewJob(name string, opts ...JobOption) *Job {
+	cfg := jobConfig{flushTimeout: DefaultJobFlushTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Job{name: name, cfg: cfg}
+}
+
+// Run runs `fn` under the span of an attempt, and retries it when it fails if
+// configured with `WithJobRetries`, until `ctx` is cancelled. The spans link to
+// the current span of `ctx`, if any. A returned error or a panic is recorded
+// against the span with `RecordError`, and the last one is returned. Panics are
+// recovered, see `PanicError`. Spans are flushed once the run is over so that
+// short-lived processes do not lose them on exit, see `Provider.ForceFlush`.
+func (j *Job) Run(ctx context.Context, fn func(context.Context) error) error {
+	defer j.flush(ctx)
+
+	links := LinksFromContexts(ctx)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var sc trace.SpanContext
+		sc, err = j.attempt(ctx, attempt, links, fn)
+		if err == nil || attempt > j.cfg.retries {
+			return err
+		}
+		if attempt == 1 {
+			links = append(links, LinksFromSpanContexts(sc)...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(j.cfg.retryDelay):
+		}
+	}
+}
+
+// attempt runs `fn` under a new root span and returns the span context along
+// with the error of `fn`.
+func (j *Job) attempt(
+	ctx context.Context,
+	attempt int,
+	links []trace.Link,
+	fn func(context.Context) error,
+) (trace.SpanContext, error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("job.name", j.name),
+		attribute.Int("job.attempt", attempt),
+	}
+	if len(j.cfg.schedule) > 0 {
+		attrs = append(attrs, attribute.String("job.schedule", j.cfg.schedule))
+	}
+
+	ctx, span := DefaultTracer().Tracer().Start(ctx, j.name,
+		trace.WithNewRoot(),
+		trace.WithLinks(links...),
+		trace.WithAttributes(append(attrs, j.cfg.attributes...)...),
+	)
+	defer span.End()
+
+	err := callRecovered(ctx, fn)
+	if err != nil {
+		span.SetAttributes(attribute.String("job.outcome", JobOutcomeFailure))
+	} else {
+		span.SetAttributes(attribute.String("job.outcome", JobOutcomeSuccess))
+	}
+	RecordError(span, err)
+
+	return span.SpanContext(), err
+}
+
+// flush exports the spans of the run. Errors are reported to the OpenTelemetry
+// error handler.
+func (j *Job) flush(ctx context.Context) {
+	if j.cfg.flushTimeout <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), j.cfg.flushTimeout)
+	defer cancel()
+
+	if err := forceFlush(ctx); err != nil {
+		otel.Handle(fmt.Errorf("flushing spans of job %q: %w", j.name, err))
+	}
+}
@@ -0,0 +1,199 @@
+package trace_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+var errJobFailed = errors.New("job failed")
+
+// flushCounter is a span processor counting calls to ForceFlush.
+type flushCounter struct {
+	sdktrace.SpanProcessor
+
+	flushes atomic.Int32
+}
+
+func (p *flushCounter) ForceFlush(ctx context.Context) error {
+	p.flushes.Add(1)
+	return p.SpanProcessor.ForceFlush(ctx)
+}
+
+// setupJobTestTracer configures a recording tracer provider counting the
+// flushes.
+func setupJobTestTracer(t *testing.T) (*tracetest.SpanRecorder, *flushCounter) {
+	t.Helper()
+
+	sr := tracetest.NewSpanRecorder()
+	fc := &flushCounter{SpanProcessor: sr}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(fc))
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	return sr, fc
+}
+
+func TestJobRun(t *testing.T) {
+	sr, fc := setupJobTestTracer(t)
+
+	ctx, parent := trace.NewSpan(context.Background(), "scheduler", nil)
+	job := trace.NewJob("cleanup", trace.WithJobSchedule("*/5 * * * *"))
+	err := job.Run(ctx, func(ctx context.Context) error {
+		_, span := trace.NewSpan(ctx, "delete-expired", nil)
+		span.End()
+		return nil
+	})
+	parent.End()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := endedSpan(t, sr, "cleanup")
+	if got.Parent().IsValid() {
+		t.Error("job span is not a root span")
+	}
+	if links := got.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("links = %v, want a link to the current span", links)
+	}
+	if child := endedSpan(t, sr, "delete-expired"); child.Parent().SpanID() != got.SpanContext().SpanID() {
+		t.Error("span of the job function is not a child of the job span")
+	}
+
+	want := map[attribute.Key]string{
+		"job.name":     "cleanup",
+		"job.schedule": "*/5 * * * *",
+		"job.attempt":  "1",
+		"job.outcome":  trace.JobOutcomeSuccess,
+	}
+	for key, value := range want {
+		if v, _ := spanAttribute(got, key); v.Emit() != value {
+			t.Errorf("%s = %q, want %q", key, v.Emit(), value)
+		}
+	}
+
+	if n := fc.flushes.Load(); n != 1 {
+		t.Errorf("got %d flushes, want 1", n)
+	}
+}
+
+func TestJobRunRetries(t *testing.T) {
+	sr, fc := setupJobTestTracer(t)
+
+	calls := 0
+	job := trace.NewJob("sync", trace.WithJobRetries(3, time.Millisecond))
+	err := job.Run(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errJobFailed
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	first := spans[0].SpanContext()
+	for i, span := range spans {
+		if v, _ := spanAttribute(span, "job.attempt"); v.AsInt64() != int64(i+1) {
+			t.Errorf("span %d job.attempt = %d, want %d", i, v.AsInt64(), i+1)
+		}
+
+		wantOutcome := trace.JobOutcomeFailure
+		if i == 2 {
+			wantOutcome = trace.JobOutcomeSuccess
+		}
+		if v, _ := spanAttribute(span, "job.outcome"); v.AsString() != wantOutcome {
+			t.Errorf("span %d job.outcome = %q, want %q", i, v.AsString(), wantOutcome)
+		}
+
+		if i == 0 {
+			continue
+		}
+		if span.SpanContext().TraceID() == first.TraceID() {
+			t.Errorf("span %d shares the trace of the first attempt", i)
+		}
+		if links := span.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != first.SpanID() {
+			t.Errorf("span %d links = %v, want a link to the first attempt", i, links)
+		}
+	}
+
+	if n := fc.flushes.Load(); n != 1 {
+		t.Errorf("got %d flushes, want 1", n)
+	}
+}
+
+func TestJobRunFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(context.Context) error
+		want func(error) bool
+	}{
+		{
+			name: "error",
+			fn: func(context.Context) error {
+				return errJobFailed
+			},
+			want: func(err error) bool { return errors.Is(err, errJobFailed) },
+		},
+		{
+			name: "panic",
+			fn: func(context.Context) error {
+				panic("boom")
+			},
+			want: func(err error) bool {
+				var pe *trace.PanicError
+				return errors.As(err, &pe)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, _ := setupJobTestTracer(t)
+
+			err := trace.NewJob("report").Run(context.Background(), tt.fn)
+			if !tt.want(err) {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			got := endedSpan(t, sr, "report")
+			if v, _ := spanAttribute(got, "job.outcome"); v.AsString() != trace.JobOutcomeFailure {
+				t.Errorf("job.outcome = %q, want %q", v.AsString(), trace.JobOutcomeFailure)
+			}
+			if len(got.Events()) == 0 || got.Events()[0].Name != "exception" {
+				t.Error("error is not recorded")
+			}
+		})
+	}
+}
+
+func TestJobRunCancelled(t *testing.T) {
+	sr, _ := setupJobTestTracer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := trace.NewJob("sync", trace.WithJobRetries(3, time.Hour), trace.WithJobFlushTimeout(0))
+	err := job.Run(ctx, func(context.Context) error {
+		cancel()
+		return errJobFailed
+	})
+	if !errors.Is(err, errJobFailed) {
+		t.Fatalf("Run() error = %v, want %v", err, errJobFailed)
+	}
+	if n := len(sr.Ended()); n != 1 {
+		t.Errorf("got %d spans, want 1", n)
+	}
+}
@@ -0,0 +1,232 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LeakedSpan describes a span which was started but not ended.
+type LeakedSpan struct {
+	Name        string
+	SpanContext trace.SpanContext
+	StartTime   time.Time
+	Stack       []byte
+}
+
+// LeakReporter is called with the spans detected as leaked by a
+// `LeakDetector`.
+type LeakReporter func(leaks []LeakedSpan)
+
+// LeakDetectorOption configures a `LeakDetector`.
+type LeakDetectorOption func(*LeakDetector)
+
+// WithLeakMaxAge configures the age after which a span which was not ended is
+// reported. When zero, spans are only reported when the detector shuts down.
+func WithLeakMaxAge(maxAge time.Duration) LeakDetectorOption {
+	return func(d *LeakDetector) {
+		d.maxAge = maxAge
+	}
+}
+
+// WithLeakReporter configures the function leaked spans are reported to. By
+// default they are written to the standard error.
+func WithLeakReporter(reporter LeakReporter) LeakDetectorOption {
+	return func(d *LeakDetector) {
+		d.reporter = reporter
+	}
+}
+
+type leakEntry struct {
+	span     LeakedSpan
+	reported bool
+}
+
+// LeakDetector is a span processor which tracks started-but-not-ended spans
+// along with the stack trace of their creation. It is meant for development
+// and tests, capturing a stack trace for every span is expensive.
+type LeakDetector struct {
+	maxAge   time.Duration
+	reporter LeakReporter
+
+	mu    sync.Mutex
+	spans map[trace.SpanID]*leakEntry
+
+	stop     chan struct{}
+	done     chan struct{}
+	shutdown sync.Once
+}
+
+var _ sdktrace.SpanProcessor = (*LeakDetector)(nil)
+
+// NewLeakDetector returns a new `LeakDetector`. It must be registered with the
+// tracer provider, see `WithSpanLeakDetection` or `sdktrace.WithSpanProcessor`.
+func NewLeakDetector(opts ...LeakDetectorOption) *LeakDetector {
+	d := &LeakDetector{
+		reporter: reportLeaks,
+		spans:    map[trace.SpanID]*leakEntry{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if d.maxAge > 0 {
+		go d.watch()
+	} else {
+		close(d.done)
+	}
+
+	return d
+}
+
+// TestingT is the subset of `testing.TB` used by `FailOnSpanLeaks`, which
+// keeps the `testing` package out of production binaries.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(fn func())
+}
+
+// FailOnSpanLeaks returns a new `LeakDetector` which fails `tb`, such as a
+// `*testing.T`, if any span it observed was not ended by the end of the test.
+// The detector must be registered with the tracer provider used by the code
+// under test.
+func FailOnSpanLeaks(tb TestingT) *LeakDetector {
+	tb.Helper()
+
+	d := NewLeakDetector(WithLeakReporter(func(leaks []LeakedSpan) {
+		tb.Helper()
+		for _, leak := range leaks {
+			tb.Errorf("span %q was not ended, started at:\n%s", leak.Name, leak.Stack)
+		}
+	}))
+	tb.Cleanup(func() {
+		_ = d.Shutdown(context.Background())
+	})
+
+	return d
+}
+
+// Leaks returns the spans which were started but not ended yet, oldest first.
+func (d *LeakDetector) Leaks() []LeakedSpan {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	leaks := make([]LeakedSpan, 0, len(d.spans))
+	for _, entry := range d.spans {
+		leaks = append(leaks, entry.span)
+	}
+	sortLeaks(leaks)
+
+	return leaks
+}
+
+// OnStart implements `sdktrace.SpanProcessor`.
+func (d *LeakDetector) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	entry := &leakEntry{
+		span: LeakedSpan{
+			Name:        s.Name(),
+			SpanContext: s.SpanContext(),
+			StartTime:   s.StartTime(),
+			Stack:       debug.Stack(),
+		},
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.spans[s.SpanContext().SpanID()] = entry
+}
+
+// OnEnd implements `sdktrace.SpanProcessor`.
+func (d *LeakDetector) OnEnd(s sdktrace.ReadOnlySpan) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.spans, s.SpanContext().SpanID())
+}
+
+// Shutdown implements `sdktrace.SpanProcessor`. It reports every span which
+// was not ended and was not reported yet.
+func (d *LeakDetector) Shutdown(ctx context.Context) error {
+	d.shutdown.Do(func() {
+		close(d.stop)
+		d.report(time.Time{})
+	})
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ForceFlush implements `sdktrace.SpanProcessor`.
+func (d *LeakDetector) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (d *LeakDetector) watch() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.maxAge)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case now := <-ticker.C:
+			d.report(now.Add(-d.maxAge))
+		}
+	}
+}
+
+// report reports the spans started before `before` which were not reported
+// yet. A zero time reports all of them.
+func (d *LeakDetector) report(before time.Time) {
+	var leaks []LeakedSpan
+
+	d.mu.Lock()
+	for _, entry := range d.spans {
+		if entry.reported || (!before.IsZero() && entry.span.StartTime.After(before)) {
+			continue
+		}
+		entry.reported = true
+		leaks = append(leaks, entry.span)
+	}
+	d.mu.Unlock()
+
+	if len(leaks) > 0 {
+		sortLeaks(leaks)
+		d.reporter(leaks)
+	}
+}
+
+func sortLeaks(leaks []LeakedSpan) {
+	sort.Slice(leaks, func(i, j int) bool {
+		return leaks[i].StartTime.Before(leaks[j].StartTime)
+	})
+}
+
+func reportLeaks(leaks []LeakedSpan) {
+	for _, leak := range leaks {
+		_, _ = fmt.Fprintf(
+			os.Stderr,
+			"trace: span %q (trace_id=%s span_id=%s) started %s ago was not ended, started at:\n%s\n",
+			leak.Name,
+			leak.SpanContext.TraceID(),
+			leak.SpanContext.SpanID(),
+			time.Since(leak.StartTime).Round(time.Millisecond),
+			leak.Stack,
+		)
+	}
+}
@@ -0,0 +1,150 @@
+package trace_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+type fakeTB struct {
+	testing.TB
+
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) runCleanups() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestLeakDetector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tracks spans which are not ended", func(t *testing.T) {
+		t.Parallel()
+
+		d := trace.NewLeakDetector(trace.WithLeakReporter(func([]trace.LeakedSpan) {}))
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(d))
+		defer func() { _ = tp.Shutdown(context.Background()) }()
+
+		_, ended := tp.Tracer("test").Start(context.Background(), "ended")
+		ended.End()
+		_, leaked := tp.Tracer("test").Start(context.Background(), "leaked")
+
+		leaks := d.Leaks()
+		if len(leaks) != 1 {
+			t.Fatalf("expected 1 leaked span, got %d", len(leaks))
+		}
+		if leaks[0].Name != "leaked" {
+			t.Errorf("expected leaked span name %q, got %q", "leaked", leaks[0].Name)
+		}
+		if len(leaks[0].Stack) == 0 {
+			t.Error("expected leaked span stack trace")
+		}
+
+		leaked.End()
+		if leaks = d.Leaks(); len(leaks) != 0 {
+			t.Errorf("expected no leaked span, got %d", len(leaks))
+		}
+	})
+
+	t.Run("reports spans older than max age", func(t *testing.T) {
+		t.Parallel()
+
+		reported := make(chan []trace.LeakedSpan, 1)
+		d := trace.NewLeakDetector(
+			trace.WithLeakMaxAge(10*time.Millisecond),
+			trace.WithLeakReporter(func(leaks []trace.LeakedSpan) { reported <- leaks }),
+		)
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(d))
+		defer func() { _ = tp.Shutdown(context.Background()) }()
+
+		_, span := tp.Tracer("test").Start(context.Background(), "slow")
+		defer span.End()
+
+		select {
+		case leaks := <-reported:
+			if len(leaks) != 1 || leaks[0].Name != "slow" {
+				t.Errorf("expected span %q to be reported, got %v", "slow", leaks)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected leaked span to be reported")
+		}
+	})
+
+	t.Run("reports spans at shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		var reported []trace.LeakedSpan
+		d := trace.NewLeakDetector(trace.WithLeakReporter(func(leaks []trace.LeakedSpan) {
+			reported = append(reported, leaks...)
+		}))
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(d))
+
+		_, span := tp.Tracer("test").Start(context.Background(), "forgotten")
+		defer span.End()
+
+		if err := tp.Shutdown(context.Background()); err != nil {
+			t.Fatalf("shutdown failed: %v", err)
+		}
+
+		if len(reported) != 1 || reported[0].Name != "forgotten" {
+			t.Errorf("expected span %q to be reported, got %v", "forgotten", reported)
+		}
+	})
+}
+
+func TestFailOnSpanLeaks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passes when spans are ended", func(t *testing.T) {
+		t.Parallel()
+
+		tb := &fakeTB{TB: t}
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(trace.FailOnSpanLeaks(tb)))
+
+		_, span := tp.Tracer("test").Start(context.Background(), "ended")
+		span.End()
+
+		tb.runCleanups()
+		_ = tp.Shutdown(context.Background())
+
+		if len(tb.errors) != 0 {
+			t.Errorf("expected no error, got %v", tb.errors)
+		}
+	})
+
+	t.Run("fails when spans leak", func(t *testing.T) {
+		t.Parallel()
+
+		tb := &fakeTB{TB: t}
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(trace.FailOnSpanLeaks(tb)))
+
+		_, span := tp.Tracer("test").Start(context.Background(), "leaked")
+		defer span.End()
+
+		tb.runCleanups()
+		_ = tp.Shutdown(context.Background())
+
+		if len(tb.errors) != 1 {
+			t.Errorf("expected 1 error, got %v", tb.errors)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LinksFromSpanContexts returns a link for every valid span context. Invalid
+// span contexts are skipped.
+func LinksFromSpanContexts(scs ...trace.SpanContext) []trace.Link {
+	links := make([]trace.Link, 0, len(scs))
+	for _, sc := range scs {
+		if sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	return links
+}
+
+// LinksFromContexts returns a link to the current span of every context. Use
+// this when a single unit of work processes items which were received with
+// their own context, such as a batch of messages.
+func LinksFromContexts(ctxs ...context.Context) []trace.Link {
+	scs := make([]trace.SpanContext, len(ctxs))
+	for i, ctx := range ctxs {
+		scs[i] = trace.SpanContextFromContext(ctx)
+	}
+
+	return LinksFromSpanContexts(scs...)
+}
+
+// LinksFromCarriers extracts a remote span context from every carrier with the
+// global propagator and returns a link to each of them. Carriers that do not
+// hold a valid span context are skipped.
+func LinksFromCarriers(ctx context.Context, carriers ...propagation.TextMapCarrier) []trace.Link {
+	prop := otel.GetTextMapPropagator()
+
+	scs := make([]trace.SpanContext, len(carriers))
+	for i, carrier := range carriers {
+		scs[i] = trace.SpanContextFromContext(prop.Extract(ctx, carrier))
+	}
+
+	return LinksFromSpanContexts(scs...)
+}
+
+// NewLinkedSpan returns a new span from the default tracer which is linked to
+// the given spans. The span is a child of the current span of `ctx`, if any.
+// Each resulting span must be completed with `defer span.End()` right after the
+// call.
+func NewLinkedSpan(
+	ctx context.Context,
+	name string,
+	links []trace.Link,
+	cus SpanCustomiser,
+) (context.Context, trace.Span) {
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return DefaultTracer().NewLinkedSpan(ctx, name, links, cus)
+}
+
+// NewDetachedSpan returns a new root span from the default tracer which links
+// back to the current span of `ctx`. The returned context is not cancelled
+// when `ctx` is, which makes it suitable for long-running background work
+// started by a short-lived operation. Each resulting span must be completed
+// with `defer span.End()` right after the call.
+func NewDetachedSpan(ctx context.Context, name string, cus SpanCustomiser) (context.Context, trace.Span) {
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return DefaultTracer().NewDetachedSpan(ctx, name, cus)
+}
+
+// NewLinkedSpan returns a new span from the handle's tracer. It behaves like
+// the package-level `NewLinkedSpan` function.
+func (t *NamedTracer) NewLinkedSpan(
+	ctx context.Context,
+	name string,
+	links []trace.Link,
+	cus SpanCustomiser,
+) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{trace.WithLinks(links...)}
+	if cus != nil {
+		opts = append(opts, cus.Customise()...)
+	}
+
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return t.Tracer().Start(ctx, name, opts...)
+}
+
+// NewDetachedSpan returns a new root span from the handle's tracer. It behaves
+// like the package-level `NewDetachedSpan` function.
+func (t *NamedTracer) NewDetachedSpan(
+	ctx context.Context,
+	name string,
+	cus SpanCustomiser,
+) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithLinks(LinksFromContexts(ctx)...),
+	}
+	if cus != nil {
+		opts = append(opts, cus.Customise()...)
+	}
+
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return t.Tracer().Start(context.WithoutCancel(ctx), name, opts...)
+}
@@ -0,0 +1,126 @@
+package trace_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestNewLinkedSpan(t *testing.T) {
+	t.Run("links to contexts", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		ctx := context.Background()
+		ctx1, producer1 := trace.NewSpan(ctx, "producer-1", nil)
+		producer1.End()
+		ctx2, producer2 := trace.NewSpan(ctx, "producer-2", nil)
+		producer2.End()
+
+		links := trace.LinksFromContexts(ctx1, ctx2, ctx)
+		if len(links) != 2 {
+			t.Fatalf("expected 2 links, got %d", len(links))
+		}
+
+		_, span := trace.NewLinkedSpan(ctx, "batch", links, nil)
+		span.End()
+
+		spans := sr.Ended()
+		if len(spans) != 3 {
+			t.Fatalf("expected 3 spans, got %d", len(spans))
+		}
+
+		batch := spans[2]
+		if len(batch.Links()) != 2 {
+			t.Fatalf("expected 2 span links, got %d", len(batch.Links()))
+		}
+		if batch.Links()[0].SpanContext.SpanID() != producer1.SpanContext().SpanID() {
+			t.Error("first link does not point to first producer span")
+		}
+		if batch.Links()[1].SpanContext.SpanID() != producer2.SpanContext().SpanID() {
+			t.Error("second link does not point to second producer span")
+		}
+	})
+
+	t.Run("links to carriers", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		prev := otel.GetTextMapPropagator()
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		defer otel.SetTextMapPropagator(prev)
+
+		ctx := context.Background()
+		producerCtx, producer := trace.NewSpan(ctx, "producer", nil)
+		producer.End()
+
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(producerCtx, carrier)
+
+		links := trace.LinksFromCarriers(ctx, carrier, propagation.MapCarrier{})
+		if len(links) != 1 {
+			t.Fatalf("expected 1 link, got %d", len(links))
+		}
+
+		_, span := trace.NewLinkedSpan(ctx, "consumer", links, &testSpanCustomiser{kind: oteltrace.SpanKindConsumer})
+		span.End()
+
+		spans := sr.Ended()
+		if len(spans) != 2 {
+			t.Fatalf("expected 2 spans, got %d", len(spans))
+		}
+
+		consumer := spans[1]
+		if consumer.SpanKind() != oteltrace.SpanKindConsumer {
+			t.Errorf("expected span kind %v, got %v", oteltrace.SpanKindConsumer, consumer.SpanKind())
+		}
+		if len(consumer.Links()) != 1 {
+			t.Fatalf("expected 1 span link, got %d", len(consumer.Links()))
+		}
+		if consumer.Links()[0].SpanContext.TraceID() != producer.SpanContext().TraceID() {
+			t.Error("link does not point to producer trace")
+		}
+	})
+}
+
+func TestNewDetachedSpan(t *testing.T) {
+	sr, cleanup := setupTestTracer()
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, parent := trace.NewSpan(ctx, "request", nil)
+
+	detachedCtx, detached := trace.NewDetachedSpan(ctx, "background", nil)
+	cancel()
+	parent.End()
+
+	if detachedCtx.Err() != nil {
+		t.Errorf("expected detached context not to be cancelled, got %v", detachedCtx.Err())
+	}
+
+	detached.End()
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	background := spans[1]
+	if background.Parent().IsValid() {
+		t.Error("expected detached span to be a root span")
+	}
+	if background.SpanContext().TraceID() == parent.SpanContext().TraceID() {
+		t.Error("expected detached span to start a new trace")
+	}
+	if len(background.Links()) != 1 {
+		t.Fatalf("expected 1 span link, got %d", len(background.Links()))
+	}
+	if background.Links()[0].SpanContext.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("link does not point to parent span")
+	}
+}
@@ -0,0 +1,136 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Message describes a message sent or received through a messaging system,
+// such as Kafka, NATS or an in-house queue.
+type Message struct {
+	// System is the messaging system, such as "kafka" or "nats".
+	System string
+	// Destination is the name of the queue or topic. Spans of messages without
+	// destination are named after "(anonymous)".
+	Destination string
+	// ID is the identifier of the message, if known.
+	ID string
+	// PayloadSize is the size of the message body in bytes, if known.
+	PayloadSize int
+	// Headers carries the trace context along with the message. It may be nil.
+	Headers propagation.TextMapCarrier
+}
+
+// MessagingOption configures the messaging span helpers.
+type MessagingOption func(*messagingConfig)
+
+type messagingConfig struct {
+	link       bool
+	attributes []attribute.KeyValue
+}
+
+func newMessagingConfig(opts []MessagingOption) *messagingConfig {
+	c := &messagingConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// messageAttributes returns the "messaging.*" attributes of the message along
+// with the configured ones.
+func (c *messagingConfig) messageAttributes(msg Message) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 4+len(c.attributes))
+	if len(msg.System) > 0 {
+		attrs = append(attrs, semconv.MessagingSystemKey.String(msg.System))
+	}
+	if len(msg.Destination) > 0 {
+		attrs = append(attrs, semconv.MessagingDestinationKey.String(msg.Destination))
+	} else {
+		attrs = append(attrs, semconv.MessagingTempDestinationKey.Bool(true))
+	}
+	if len(msg.ID) > 0 {
+		attrs = append(attrs, semconv.MessagingMessageIDKey.String(msg.ID))
+	}
+	if msg.PayloadSize > 0 {
+		attrs = append(attrs, semconv.MessagingMessagePayloadSizeBytesKey.Int(msg.PayloadSize))
+	}
+
+	return append(attrs, c.attributes...)
+}
+
+// WithConsumerLink starts consumer spans as children of the current span of
+// the context, such as the span of the polling loop, linked to the producer
+// span rather than as its children. Use this when messages are processed in
+// batches or long after they were produced. Producer spans ignore it.
+func WithConsumerLink() MessagingOption {
+	return func(c *messagingConfig) {
+		c.link = true
+	}
+}
+
+// WithMessagingAttributes adds attributes to the span, such as
+// `semconv.MessagingKafkaPartitionKey`.
+func WithMessagingAttributes(attrs ...attribute.KeyValue) MessagingOption {
+	return func(c *messagingConfig) {
+		c.attributes = append(c.attributes, attrs...)
+	}
+}
+
+// StartProducerSpan starts a producer span named "<destination> send" as a
+// child of the current span of `ctx`, and injects its span context and the
+// baggage of `ctx` into the headers of the message with the propagators
+// configured by `NewProvider`. The span must be ended once the message was
+// sent.
+func StartProducerSpan(ctx context.Context, msg Message, opts ...MessagingOption) (context.Context, trace.Span) {
+	cfg := newMessagingConfig(opts)
+
+	ctx, span := DefaultTracer().Tracer().Start(ctx, messageSpanName(msg, "send"),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(cfg.messageAttributes(msg)...),
+	)
+	if msg.Headers != nil {
+		Inject(ctx, msg.Headers)
+	}
+
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return ctx, span
+}
+
+// StartConsumerSpan starts a consumer span named "<destination> process" for
+// a received message. The span is a child of the producer span found in the
+// headers of the message, and the returned context holds its baggage, unless
+// `WithConsumerLink` is set. The span must be ended once the message was
+// processed.
+func StartConsumerSpan(ctx context.Context, msg Message, opts ...MessagingOption) (context.Context, trace.Span) {
+	cfg := newMessagingConfig(opts)
+
+	spanOpts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(cfg.messageAttributes(msg)...),
+		trace.WithAttributes(semconv.MessagingOperationProcess),
+	}
+	if msg.Headers != nil {
+		if cfg.link {
+			sc := trace.SpanContextFromContext(Extract(context.Background(), msg.Headers))
+			spanOpts = append(spanOpts, trace.WithLinks(LinksFromSpanContexts(sc)...))
+		} else {
+			ctx = Extract(ctx, msg.Headers)
+		}
+	}
+
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return DefaultTracer().Tracer().Start(ctx, messageSpanName(msg, "process"), spanOpts...)
+}
+
+func messageSpanName(msg Message, operation string) string {
+	if len(msg.Destination) == 0 {
+		return "(anonymous) " + operation
+	}
+	return msg.Destination + " " + operation
+}
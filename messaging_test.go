@@ -0,0 +1,137 @@
+package trace_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestStartProducerSpan(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	headers := trace.MapCarrier{}
+	_, span := trace.StartProducerSpan(context.Background(), trace.Message{
+		System:      "kafka",
+		Destination: "orders",
+		ID:          "42",
+		PayloadSize: 128,
+		Headers:     headers,
+	}, trace.WithMessagingAttributes(attribute.Int("messaging.kafka.partition", 3)))
+	span.End()
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	if got.Name() != "orders send" {
+		t.Errorf("name = %q, want %q", got.Name(), "orders send")
+	}
+	if got.SpanKind() != oteltrace.SpanKindProducer {
+		t.Errorf("span kind = %v, want %v", got.SpanKind(), oteltrace.SpanKindProducer)
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"messaging.system":                     attribute.StringValue("kafka"),
+		"messaging.destination":                attribute.StringValue("orders"),
+		"messaging.message_id":                 attribute.StringValue("42"),
+		"messaging.message_payload_size_bytes": attribute.IntValue(128),
+		"messaging.kafka.partition":            attribute.IntValue(3),
+	}
+	for key, value := range want {
+		if v, _ := spanAttribute(got, key); v != value {
+			t.Errorf("%s = %v, want %v", key, v.Emit(), value.Emit())
+		}
+	}
+
+	sc := oteltrace.SpanContextFromContext(trace.Extract(context.Background(), headers))
+	if sc.SpanID() != got.SpanContext().SpanID() {
+		t.Errorf("injected span ID = %s, want %s", sc.SpanID(), got.SpanContext().SpanID())
+	}
+}
+
+func TestStartConsumerSpan(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []trace.MessagingOption
+		link   bool
+		tenant string
+	}{
+		{name: "parent", tenant: "acme"},
+		{name: "link", opts: []trace.MessagingOption{trace.WithConsumerLink()}, link: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupHTTPTestTracer(t)
+
+			member, _ := baggage.NewMember("tenant.id", "acme")
+			bag, _ := baggage.New(member)
+			producerCtx := baggage.ContextWithBaggage(context.Background(), bag)
+
+			headers := trace.MapCarrier{}
+			_, producer := trace.StartProducerSpan(producerCtx, trace.Message{Destination: "orders", Headers: headers})
+			producer.End()
+
+			pollCtx, poll := trace.NewSpan(context.Background(), "poll", nil)
+			ctx, consumer := trace.StartConsumerSpan(pollCtx, trace.Message{
+				System:      "kafka",
+				Destination: "orders",
+				Headers:     headers,
+			}, tt.opts...)
+			consumer.End()
+			poll.End()
+
+			got := endedSpan(t, sr, "orders process")
+			if got.SpanKind() != oteltrace.SpanKindConsumer {
+				t.Errorf("span kind = %v, want %v", got.SpanKind(), oteltrace.SpanKindConsumer)
+			}
+			if v, _ := spanAttribute(got, "messaging.operation"); v.AsString() != "process" {
+				t.Errorf("messaging.operation = %q, want %q", v.AsString(), "process")
+			}
+
+			wantParent := producer.SpanContext().SpanID()
+			if tt.link {
+				wantParent = poll.SpanContext().SpanID()
+				links := got.Links()
+				if len(links) != 1 || links[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+					t.Errorf("links = %v, want a link to the producer span", links)
+				}
+			} else if len(got.Links()) != 0 {
+				t.Errorf("got %d links, want none", len(got.Links()))
+			}
+			if got.Parent().SpanID() != wantParent {
+				t.Errorf("parent span ID = %s, want %s", got.Parent().SpanID(), wantParent)
+			}
+
+			if tenant := baggage.FromContext(ctx).Member("tenant.id").Value(); tenant != tt.tenant {
+				t.Errorf("baggage tenant.id = %q, want %q", tenant, tt.tenant)
+			}
+		})
+	}
+}
+
+func TestStartConsumerSpanWithoutContext(t *testing.T) {
+	sr := setupHTTPTestTracer(t)
+
+	pollCtx, poll := trace.NewSpan(context.Background(), "poll", nil)
+	_, consumer := trace.StartConsumerSpan(pollCtx, trace.Message{Headers: trace.MapCarrier{}}, trace.WithConsumerLink())
+	consumer.End()
+	poll.End()
+
+	got := endedSpan(t, sr, "(anonymous) process")
+	if got.Parent().SpanID() != poll.SpanContext().SpanID() {
+		t.Error("consumer span is not a child of the current span")
+	}
+	if len(got.Links()) != 0 {
+		t.Errorf("got %d links, want none", len(got.Links()))
+	}
+	if v, _ := spanAttribute(got, "messaging.temp_destination"); !v.AsBool() {
+		t.Error("messaging.temp_destination is not set")
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/contrib/propagators/ot"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ErrUnsupportedPropagators is returned when none of the configured
+// propagators is registered.
+var ErrUnsupportedPropagators = errors.New("invalid configuration: unsupported propagators")
+
+// propagatorNone disables propagation when listed in OTEL_PROPAGATORS.
+const propagatorNone = "none"
+
+// PropagatorFactory returns a new propagator for a name listed in
+// OTEL_PROPAGATORS.
+type PropagatorFactory func() propagation.TextMapPropagator
+
+type propagatorRegistry struct {
+	mu        sync.RWMutex
+	names     []string
+	factories map[string]PropagatorFactory
+}
+
+//nolint:gochecknoglobals // Registry shared by all providers, see RegisterPropagator.
+var propagators = newPropagatorRegistry()
+
+func newPropagatorRegistry() *propagatorRegistry {
+	r := &propagatorRegistry{factories: map[string]PropagatorFactory{}}
+	r.register("b3", func() propagation.TextMapPropagator {
+		return b3.New(b3.WithInjectEncoding(b3.B3SingleHeader))
+	})
+	r.register("b3multi", func() propagation.TextMapPropagator {
+		return b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader))
+	})
+	r.register("baggage", func() propagation.TextMapPropagator { return propagation.Baggage{} })
+	r.register("tracecontext", func() propagation.TextMapPropagator { return propagation.TraceContext{} })
+	r.register("ottrace", func() propagation.TextMapPropagator { return ot.OT{} })
+	r.register("jaeger", func() propagation.TextMapPropagator { return jaeger.Jaeger{} })
+	r.register("xray", func() propagation.TextMapPropagator { return xray.Propagator{} })
+
+	return r
+}
+
+// RegisterPropagator registers a propagator factory under the name so that it
+// can be listed in OTEL_PROPAGATORS or the propagators of `Config`. Registering
+// an existing name replaces its factory, including the built-in ones. It is
+// meant to be called at program start, before `InitProvider`.
+func RegisterPropagator(name string, factory PropagatorFactory) {
+	propagators.register(name, factory)
+}
+
+func (r *propagatorRegistry) register(name string, factory PropagatorFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.factories[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.factories[name] = factory
+}
+
+// newPropagator returns a composite of the propagators registered under the
+// names. Unknown names are ignored, but at least one must be known unless
+// "none" is listed.
+func (r *propagatorRegistry) newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var props []propagation.TextMapPropagator
+	disabled := false
+	for _, name := range names {
+		if name == propagatorNone {
+			disabled = true
+			continue
+		}
+		if factory, ok := r.factories[name]; ok {
+			props = append(props, factory())
+		}
+	}
+	if len(props) == 0 && !disabled {
+		supported := append(slices.Clone(r.names), propagatorNone)
+		return nil, fmt.Errorf("%w. Supported options: %s", ErrUnsupportedPropagators, strings.Join(supported, ","))
+	}
+
+	return propagation.NewCompositeTextMapPropagator(props...), nil
+}
+
+// directionalPropagator extracts and injects with different propagators.
+type directionalPropagator struct {
+	extract propagation.TextMapPropagator
+	inject  propagation.TextMapPropagator
+}
+
+func (p directionalPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	p.inject.Inject(ctx, carrier)
+}
+
+func (p directionalPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return p.extract.Extract(ctx, carrier)
+}
+
+func (p directionalPropagator) Fields() []string {
+	return p.inject.Fields()
+}
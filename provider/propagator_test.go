@@ -0,0 +1,103 @@
+package provider_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace/provider"
+)
+
+type correlationKey struct{}
+
+// correlationPropagator propagates a correlation ID in the "x-correlation-id"
+// header.
+type correlationPropagator struct{}
+
+func (correlationPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	if id, ok := ctx.Value(correlationKey{}).(string); ok {
+		carrier.Set("x-correlation-id", id)
+	}
+}
+
+func (correlationPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	if id := carrier.Get("x-correlation-id"); id != "" {
+		return context.WithValue(ctx, correlationKey{}, id)
+	}
+	return ctx
+}
+
+func (correlationPropagator) Fields() []string {
+	return []string{"x-correlation-id"}
+}
+
+func TestRegisterPropagator(t *testing.T) {
+	provider.RegisterPropagator("correlation", func() propagation.TextMapPropagator {
+		return correlationPropagator{}
+	})
+
+	testInitProviderSuccess(t, provider.Config{
+		Endpoint:      "localhost:4317",
+		Insecure:      true,
+		Headers:       map[string]string{},
+		Resource:      createTestResource(t),
+		TraceExporter: createTestExporter(t),
+		Propagators:   []string{"tracecontext", "correlation"},
+	})
+
+	ctx := context.WithValue(context.Background(), correlationKey{}, "abc")
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	if carrier.Get("x-correlation-id") != "abc" {
+		t.Errorf("expected correlation ID %q, got %q", "abc", carrier.Get("x-correlation-id"))
+	}
+
+	_, err := provider.InitProvider(provider.Config{
+		Resource:      createTestResource(t),
+		TraceExporter: createTestExporter(t),
+		Propagators:   []string{"unknown"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "correlation") {
+		t.Errorf("expected registered propagator in supported options, got %v", err)
+	}
+}
+
+func TestInitProviderDirectionalPropagators(t *testing.T) {
+	testInitProviderSuccess(t, provider.Config{
+		Endpoint:           "localhost:4317",
+		Insecure:           true,
+		Headers:            map[string]string{},
+		Resource:           createTestResource(t),
+		TraceExporter:      createTestExporter(t),
+		Propagators:        []string{"b3"},
+		ExtractPropagators: []string{"b3", "tracecontext"},
+		InjectPropagators:  []string{"tracecontext"},
+	})
+
+	prop := otel.GetTextMapPropagator()
+
+	incoming := propagation.MapCarrier{"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"}
+	ctx := prop.Extract(context.Background(), incoming)
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.TraceID().String() != "80f198ee56343ba864fe8b2a57d3eff7" {
+		t.Errorf("expected B3 trace ID to be extracted, got %q", sc.TraceID())
+	}
+
+	outgoing := propagation.MapCarrier{}
+	prop.Inject(ctx, outgoing)
+	if outgoing.Get("traceparent") == "" {
+		t.Error("expected traceparent header to be injected")
+	}
+	if outgoing.Get("b3") != "" {
+		t.Error("expected b3 header not to be injected")
+	}
+
+	if fields := prop.Fields(); len(fields) != 2 {
+		t.Errorf("expected inject fields, got %v", fields)
+	}
+}
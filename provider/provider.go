@@ -2,15 +2,12 @@ package provider
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"slices"
 
-	"go.opentelemetry.io/contrib/propagators/b3"
-	"go.opentelemetry.io/contrib/propagators/ot"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc/credentials"
@@ -18,13 +15,16 @@ import (
 )
 
 type Config struct {
-	Endpoint        string
-	Insecure        bool
-	Headers         map[string]string
-	Resource        *resource.Resource
-	TraceExporter   trace.SpanExporter
-	ReportingPeriod string
-	Propagators     []string
+	Endpoint           string
+	Insecure           bool
+	Headers            map[string]string
+	Resource           *resource.Resource
+	TraceExporter      trace.SpanExporter
+	ReportingPeriod    string
+	Propagators        []string
+	ExtractPropagators []string
+	InjectPropagators  []string
+	SpanProcessors     []trace.SpanProcessor
 }
 
 type ShutdownFunc func() error
@@ -45,11 +45,16 @@ func InitProvider(c Config) (ShutdownFunc, error) {
 		bsp = trace.NewBatchSpanProcessor(c.TraceExporter)
 	}
 
-	tracerProvider := trace.NewTracerProvider(
+	opts := []trace.TracerProviderOption{
 		trace.WithSampler(trace.AlwaysSample()),
 		trace.WithSpanProcessor(bsp),
 		trace.WithResource(c.Resource),
-	)
+	}
+	for _, sp := range c.SpanProcessors {
+		opts = append(opts, trace.WithSpanProcessor(sp))
+	}
+
+	tracerProvider := trace.NewTracerProvider(opts...)
 
 	if cfgErr := configurePropagators(c); cfgErr != nil {
 		return nil, cfgErr
@@ -79,28 +84,37 @@ func newTraceExporter(endpoint string, insecure bool, headers map[string]string)
 	)
 }
 
-// configurePropagators configures B3 propagation by default.
+// configurePropagators configures the propagators listed in the configuration
+// following the OTEL_PROPAGATORS specification: "b3" injects the B3 single
+// header and "b3multi" the B3 multiple headers, "none" disables propagation.
+// The extract and inject lists, when set, replace the common list for their
+// direction.
 func configurePropagators(c Config) error {
-	propagatorsMap := map[string]propagation.TextMapPropagator{
-		"b3":           b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
-		"baggage":      propagation.Baggage{},
-		"tracecontext": propagation.TraceContext{},
-		"ottrace":      ot.OT{},
+	extract := c.Propagators
+	if len(c.ExtractPropagators) > 0 {
+		extract = c.ExtractPropagators
+	}
+	inject := c.Propagators
+	if len(c.InjectPropagators) > 0 {
+		inject = c.InjectPropagators
 	}
-	var props []propagation.TextMapPropagator
-	for _, key := range c.Propagators {
-		prop := propagatorsMap[key]
-		if prop != nil {
-			props = append(props, prop)
-		}
+
+	extractProp, err := propagators.newPropagator(extract)
+	if err != nil {
+		return err
+	}
+	if slices.Equal(extract, inject) {
+		otel.SetTextMapPropagator(extractProp)
+		return nil
 	}
-	if len(props) == 0 {
-		return errors.New(
-			"invalid configuration: unsupported propagators. Supported options: b3,baggage,tracecontext,ottrace",
-		)
+
+	injectProp, err := propagators.newPropagator(inject)
+	if err != nil {
+		return err
 	}
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		props...,
-	))
+	otel.SetTextMapPropagator(directionalPropagator{
+		extract: extractProp,
+		inject:  injectProp,
+	})
 	return nil
 }
@@ -2,6 +2,8 @@ package provider_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 
 	"go.opentelemetry.io/otel"
@@ -104,9 +106,8 @@ func TestInitProvider(t *testing.T) {
 			t.Error("expected error for invalid propagators, got nil")
 		}
 
-		expectedErrMsg := "invalid configuration: unsupported propagators. Supported options: b3,baggage,tracecontext,ottrace"
-		if err.Error() != expectedErrMsg {
-			t.Errorf("expected error %q, got %q", expectedErrMsg, err.Error())
+		if !errors.Is(err, provider.ErrUnsupportedPropagators) {
+			t.Errorf("expected error %q, got %q", provider.ErrUnsupportedPropagators, err)
 		}
 	})
 
@@ -158,6 +159,87 @@ func TestInitProvider(t *testing.T) {
 	})
 }
 
+func TestInitProviderPropagators(t *testing.T) {
+	tests := []struct {
+		name        string
+		propagators []string
+		fields      []string
+	}{
+		{name: "b3 single header", propagators: []string{"b3"}, fields: []string{"b3"}},
+		{
+			name:        "b3 multiple headers",
+			propagators: []string{"b3multi"},
+			fields:      []string{"x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-flags"},
+		},
+		{name: "jaeger", propagators: []string{"jaeger"}, fields: []string{"uber-trace-id"}},
+		{name: "xray", propagators: []string{"xray"}, fields: []string{"X-Amzn-Trace-Id"}},
+		{name: "none", propagators: []string{"none"}, fields: nil},
+		{
+			name:        "none with others",
+			propagators: []string{"none", "tracecontext"},
+			fields:      []string{"traceparent", "tracestate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testInitProviderSuccess(t, provider.Config{
+				Endpoint:      "localhost:4317",
+				Insecure:      true,
+				Headers:       map[string]string{},
+				Resource:      createTestResource(t),
+				TraceExporter: createTestExporter(t),
+				Propagators:   tt.propagators,
+			})
+
+			fields := otel.GetTextMapPropagator().Fields()
+			slices.Sort(fields)
+			slices.Sort(tt.fields)
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("expected propagator fields %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestInitProviderWithSpanProcessors(t *testing.T) {
+	t.Parallel()
+
+	sp := &recordingSpanProcessor{}
+	cfg := provider.Config{
+		Endpoint:       "localhost:4317",
+		Insecure:       true,
+		Headers:        map[string]string{},
+		Resource:       createTestResource(t),
+		TraceExporter:  createTestExporter(t),
+		Propagators:    []string{"b3"},
+		SpanProcessors: []tracesdk.SpanProcessor{sp},
+	}
+
+	testInitProviderSuccess(t, cfg)
+
+	if !sp.shutdown {
+		t.Error("expected span processor to be shut down with the provider")
+	}
+}
+
+type recordingSpanProcessor struct {
+	shutdown bool
+}
+
+func (p *recordingSpanProcessor) OnStart(context.Context, tracesdk.ReadWriteSpan) {}
+
+func (p *recordingSpanProcessor) OnEnd(tracesdk.ReadOnlySpan) {}
+
+func (p *recordingSpanProcessor) Shutdown(context.Context) error {
+	p.shutdown = true
+	return nil
+}
+
+func (p *recordingSpanProcessor) ForceFlush(context.Context) error {
+	return nil
+}
+
 func TestShutdownFunc(t *testing.T) {
 	t.Parallel()
 
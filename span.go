@@ -3,23 +3,18 @@ package trace
 import (
 	"context"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// NewSpan returns a new span from the global tracer. Depending on the `cus`
-// argument, the span is either a plain one or a customised one. Each resulting
-// span must be completed with `defer span.End()` right after the call.
+// NewSpan returns a new span from the default tracer, see `DefaultTracer`.
+// Depending on the `cus` argument, the span is either a plain one or a
+// customised one. Each resulting span must be completed with `defer span.End()`
+// right after the call.
 func NewSpan(ctx context.Context, name string, cus SpanCustomiser) (context.Context, trace.Span) {
-	if cus == nil {
-		//nolint:spancheck // Caller is responsible for calling span.End()
-		return otel.Tracer("").Start(ctx, name)
-	}
-
 	//nolint:spancheck // Caller is responsible for calling span.End()
-	return otel.Tracer("").Start(ctx, name, cus.Customise()...)
+	return DefaultTracer().NewSpan(ctx, name, cus)
 }
 
 // SpanFromContext returns the current span from a context. If you wish to avoid
@@ -64,9 +59,16 @@ func AddSpanEvents(span trace.Span, name string, events map[string]string) {
 // AddSpanError adds a new event to the span. It will appear under the "Logs"
 // section of the selected span. This is not going to flag the span as "failed".
 // Use this if you think you should log any exceptions such as critical, error,
-// warning, caution etc. Avoid logging sensitive data!
+// warning, caution etc. Avoid logging sensitive data! Joined errors are added
+// as one event each, see `RecordError`.
 func AddSpanError(span trace.Span, err error) {
-	span.RecordError(err)
+	if err == nil {
+		return
+	}
+
+	for _, e := range splitErrors(err) {
+		recordException(span, e, errorConfig{})
+	}
 }
 
 // FailSpan flags the span as "failed" and adds "error" label on listed trace.
@@ -0,0 +1,599 @@
+package trace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrUnsupportedTxOptions is returned when beginning a transaction with an
+// isolation level or in read-only mode with a driver which supports neither.
+var ErrUnsupportedTxOptions = errors.New("driver does not support non-default transaction options")
+
+// SQLOption configures the database/sql instrumentation helpers.
+type SQLOption func(*sqlConfig)
+
+type sqlConfig struct {
+	system   string
+	raw      bool
+	skipPing bool
+}
+
+func newSQLConfig(opts []SQLOption) *sqlConfig {
+	c := &sqlConfig{system: semconv.DBSystemOtherSQL.Value.AsString()}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithDBSystem configures the "db.system" attribute of the spans, such as
+// "postgresql" or "mysql". It is "other_sql" by default. Statements of "mysql"
+// and "mariadb" are sanitised with `SanitizeMySQL`, others with `SanitizeSQL`.
+func WithDBSystem(system string) SQLOption {
+	return func(c *sqlConfig) {
+		c.system = system
+	}
+}
+
+// WithRawDBStatements records the statements as they are in the "db.statement"
+// attribute. By default, literals are replaced with "?", see `WithDBSystem`, as
+// they may hold personal data.
+func WithRawDBStatements() SQLOption {
+	return func(c *sqlConfig) {
+		c.raw = true
+	}
+}
+
+// WithSkipDBPing disables the spans of connection pings and session resets,
+// which the connection pool issues frequently.
+func WithSkipDBPing() SQLOption {
+	return func(c *sqlConfig) {
+		c.skipPing = true
+	}
+}
+
+// WrapDriver returns a driver creating spans for the queries, executions,
+// transactions and prepared statements made through the driver. Spans are
+// children of the current span of the context given to the `database/sql`
+// methods, such as `sql.DB.QueryContext`.
+func WrapDriver(d driver.Driver, opts ...SQLOption) driver.Driver {
+	return &sqlDriver{Driver: d, cfg: newSQLConfig(opts)}
+}
+
+// OpenDB opens a database like `sql.Open`, with its driver wrapped with
+// `WrapDriver`.
+func OpenDB(driverName, dataSourceName string, opts ...SQLOption) (*sql.DB, error) {
+	// `database/sql` only exposes the registered drivers through `sql.Open`,
+	// which does not connect. Its connector is closed right away.
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	d := &sqlDriver{Driver: db.Driver(), cfg: newSQLConfig(opts)}
+	_ = db.Close()
+
+	connector, err := d.OpenConnector(dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s connector: %w", driverName, err)
+	}
+
+	return sql.OpenDB(connector), nil
+}
+
+func (c *sqlConfig) start(
+	ctx context.Context,
+	name, query string,
+	opts ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{semconv.DBSystemKey.String(c.system)}
+	if len(query) > 0 {
+		if !c.raw {
+			query = c.sanitize(query)
+		}
+		attrs = append(attrs, semconv.DBStatementKey.String(query))
+	}
+
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return DefaultTracer().Tracer().Start(ctx, name, append([]trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	}, opts...)...)
+}
+
+// sanitize strips the literals of the query in the dialect of the system.
+func (c *sqlConfig) sanitize(query string) string {
+	switch c.system {
+	case semconv.DBSystemMySQL.Value.AsString(), semconv.DBSystemMariaDB.Value.AsString():
+		return SanitizeMySQL(query)
+	default:
+		return SanitizeSQL(query)
+	}
+}
+
+// end records the error, unless it asks `database/sql` to fall back to
+// another method, and ends the span.
+func (c *sqlConfig) end(span trace.Span, err error) {
+	if !errors.Is(err, driver.ErrSkip) {
+		RecordError(span, err)
+	}
+	span.End()
+}
+
+type sqlDriver struct {
+	driver.Driver
+
+	cfg *sqlConfig
+}
+
+var _ driver.DriverContext = (*sqlDriver)(nil)
+
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn, cfg: d.cfg}, nil
+}
+
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlConnector{Connector: connector, driver: d}, nil
+	}
+
+	return &sqlConnector{Connector: dsnConnector{name: name, driver: d.Driver}, driver: d}, nil
+}
+
+// dsnConnector is the connector of drivers which do not implement
+// `driver.DriverContext`, as `database/sql` does.
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type sqlConnector struct {
+	driver.Connector
+
+	driver *sqlDriver
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	ctx, span := c.driver.cfg.start(ctx, "db.connect", "")
+	conn, err := c.Connector.Connect(ctx)
+	c.driver.cfg.end(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConn{Conn: conn, cfg: c.driver.cfg}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close closes the wrapped connector, if it implements `io.Closer`, when the
+// `sql.DB` is closed.
+func (c *sqlConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+type sqlConn struct {
+	driver.Conn
+
+	cfg *sqlConfig
+}
+
+var (
+	_ driver.ConnPrepareContext = (*sqlConn)(nil)
+	_ driver.ConnBeginTx        = (*sqlConn)(nil)
+	_ driver.ExecerContext      = (*sqlConn)(nil)
+	_ driver.QueryerContext     = (*sqlConn)(nil)
+	_ driver.Pinger             = (*sqlConn)(nil)
+	_ driver.SessionResetter    = (*sqlConn)(nil)
+	_ driver.Validator          = (*sqlConn)(nil)
+	_ driver.NamedValueChecker  = (*sqlConn)(nil)
+)
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	ctx, span := c.cfg.start(ctx, "db.prepare", query)
+
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Prepare(query)
+	}
+	c.cfg.end(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := &sqlStmt{Stmt: stmt, conn: c.Conn, query: query, cfg: c.cfg}
+	if converter, ok := stmt.(driver.ColumnConverter); ok {
+		return &sqlConverterStmt{sqlStmt: wrapped, converter: converter}, nil
+	}
+	return wrapped, nil
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	spanCtx, span := c.cfg.start(ctx, "db.begin", "")
+
+	var tx driver.Tx
+	var err error
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = b.BeginTx(spanCtx, opts)
+	} else if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		err = ErrUnsupportedTxOptions
+	} else {
+		tx, err = c.Begin() //nolint:staticcheck // Fallback of drivers without BeginTx.
+	}
+	c.cfg.end(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlTx{Tx: tx, ctx: ctx, cfg: c.cfg}, nil
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	// The span is created afterwards since the driver may ask `database/sql` to
+	// fall back to a prepared statement, which is traced on its own.
+	start := time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	_, span := c.cfg.start(ctx, "db.exec", query, trace.WithTimestamp(start))
+	c.cfg.end(span, err)
+
+	return res, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	// See ExecContext.
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	_, span := c.cfg.start(ctx, "db.query", query, trace.WithTimestamp(start))
+	c.cfg.end(span, err)
+
+	return rows, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	p, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	if c.cfg.skipPing {
+		return p.Ping(ctx)
+	}
+
+	ctx, span := c.cfg.start(ctx, "db.ping", "")
+	err := p.Ping(ctx)
+	c.cfg.end(span, err)
+
+	return err
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	r, ok := c.Conn.(driver.SessionResetter)
+	if !ok {
+		return nil
+	}
+	if c.cfg.skipPing {
+		return r.ResetSession(ctx)
+	}
+
+	ctx, span := c.cfg.start(ctx, "db.reset_session", "")
+	err := r.ResetSession(ctx)
+	c.cfg.end(span, err)
+
+	return err
+}
+
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type sqlStmt struct {
+	driver.Stmt
+
+	conn  driver.Conn
+	query string
+	cfg   *sqlConfig
+}
+
+var (
+	_ driver.StmtExecContext   = (*sqlStmt)(nil)
+	_ driver.StmtQueryContext  = (*sqlStmt)(nil)
+	_ driver.NamedValueChecker = (*sqlStmt)(nil)
+)
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := s.cfg.start(ctx, "db.exec", s.query)
+
+	var res driver.Result
+	var err error
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			res, err = s.Exec(values) //nolint:staticcheck // Fallback of drivers without ExecContext.
+		}
+	}
+	s.cfg.end(span, err)
+
+	return res, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := s.cfg.start(ctx, "db.query", s.query)
+
+	var rows driver.Rows
+	var err error
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.Query(values) //nolint:staticcheck // Fallback of drivers without QueryContext.
+		}
+	}
+	s.cfg.end(span, err)
+
+	return rows, err
+}
+
+// CheckNamedValue delegates to the checker of the statement or, as
+// `database/sql` does when the statement has none, of the connection.
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	if checker, ok := s.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// sqlConverterStmt is the statement of drivers whose statements implement
+// `driver.ColumnConverter`, which `database/sql` uses after `ErrSkip`.
+type sqlConverterStmt struct {
+	*sqlStmt
+
+	converter driver.ColumnConverter
+}
+
+var _ driver.ColumnConverter = (*sqlConverterStmt)(nil)
+
+func (s *sqlConverterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.converter.ColumnConverter(idx)
+}
+
+// namedValues converts arguments for drivers which do not support named
+// parameters, as `database/sql` does.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if len(arg.Name) > 0 {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// sqlTx creates the spans of the end of a transaction under the span which
+// was current when it began.
+type sqlTx struct {
+	driver.Tx
+
+	ctx context.Context
+	cfg *sqlConfig
+}
+
+func (t *sqlTx) Commit() error {
+	_, span := t.cfg.start(t.ctx, "db.commit", "")
+	err := t.Tx.Commit()
+	t.cfg.end(span, err)
+
+	return err
+}
+
+func (t *sqlTx) Rollback() error {
+	_, span := t.cfg.start(t.ctx, "db.rollback", "")
+	err := t.Tx.Rollback()
+	t.cfg.end(span, err)
+
+	return err
+}
+
+// SanitizeSQL replaces the string and numeric literals of a statement with
+// "?", such as "SELECT name FROM users WHERE id = ?" for
+// "SELECT name FROM users WHERE id = 42". Identifiers, placeholders and
+// comments are kept. Strings follow standard SQL, as in PostgreSQL: quotes are
+// escaped by doubling them, backslashes only escape in E'...' strings, and
+// dollar-quoted strings are supported. Use `SanitizeMySQL` for MySQL.
+func SanitizeSQL(query string) string {
+	return sanitizeSQL(query, false)
+}
+
+// SanitizeMySQL is like `SanitizeSQL` for MySQL and MariaDB statements, where
+// backslashes escape characters in strings and double quotes delimit strings
+// rather than identifiers.
+func SanitizeMySQL(query string) string {
+	return sanitizeSQL(query, true)
+}
+
+func sanitizeSQL(query string, mysql bool) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i, c, mysql || isEscapeStringPrefix(query, i))
+			b.WriteByte('?')
+		case c == '"' && mysql:
+			i = skipQuoted(query, i, c, true)
+			b.WriteByte('?')
+		case c == '"' || c == '`':
+			j := skipQuoted(query, i, c, false)
+			b.WriteString(query[i:j])
+			i = j
+		case c == '$' && (i == 0 || !isIdentifierChar(query[i-1])) && len(dollarTag(query[i:])) > 0:
+			i = skipDollarQuoted(query, i, dollarTag(query[i:]))
+			b.WriteByte('?')
+		case strings.HasPrefix(query[i:], "--"):
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query) - i
+			}
+			b.WriteString(query[i : i+j])
+			i += j
+		case strings.HasPrefix(query[i:], "/*"):
+			j := strings.Index(query[i+2:], "*/")
+			if j < 0 {
+				j = len(query) - i
+			} else {
+				j += len("/**/")
+			}
+			b.WriteString(query[i : i+j])
+			i += j
+		case isDigit(c) && (i == 0 || !isIdentifierChar(query[i-1])):
+			i = skipNumber(query, i)
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// skipQuoted returns the index following the quoted string starting at `i`.
+// Quotes are escaped by doubling them or, when `backslashEscapes` is true,
+// with a backslash.
+func skipQuoted(s string, i int, quote byte, backslashEscapes bool) int {
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '\\' && backslashEscapes:
+			j++
+		case s[j] == quote && j+1 < len(s) && s[j+1] == quote:
+			j++
+		case s[j] == quote:
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// isEscapeStringPrefix reports whether the string starting at `i` is a
+// PostgreSQL escape string, such as E'\n'.
+func isEscapeStringPrefix(s string, i int) bool {
+	return i > 0 && (s[i-1] == 'E' || s[i-1] == 'e') && (i == 1 || !isIdentifierChar(s[i-2]))
+}
+
+// dollarTag returns the opening delimiter of the dollar-quoted string `s`
+// starts with, such as "$$" or "$tag$", or "" if there is none. Positional
+// parameters such as "$1" are not delimiters.
+func dollarTag(s string) string {
+	for k := 1; k < len(s); k++ {
+		c := s[k]
+		switch {
+		case c == '$':
+			return s[:k+1]
+		case isDigit(c) && k == 1, !isIdentifierChar(c):
+			return ""
+		}
+	}
+	return ""
+}
+
+// skipDollarQuoted returns the index following the dollar-quoted string
+// starting at `i` with the delimiter `tag`.
+func skipDollarQuoted(s string, i int, tag string) int {
+	j := strings.Index(s[i+len(tag):], tag)
+	if j < 0 {
+		return len(s)
+	}
+	return i + len(tag) + j + len(tag)
+}
+
+// skipNumber returns the index following the number starting at `i`, which
+// may be a decimal, hexadecimal or exponent notation.
+func skipNumber(s string, i int) int {
+	j := i
+	for j < len(s) {
+		switch c := s[j]; {
+		case (c == 'e' || c == 'E') && j+1 < len(s) && (s[j+1] == '+' || s[j+1] == '-'):
+			j += 2
+		case isIdentifierChar(c) || c == '.':
+			j++
+		default:
+			return j
+		}
+	}
+	return j
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierChar(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c >= 0x80
+}
@@ -0,0 +1,581 @@
+package trace_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+var errFakeQuery = errors.New("syntax error")
+
+// fakeDriver opens connections which only implement `driver.Conn`, so that
+// `database/sql` falls back to prepared statements.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{query: query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+// fakeContextDriver opens connections which implement the context-aware
+// interfaces.
+type fakeContextDriver struct{}
+
+func (fakeContextDriver) Open(string) (driver.Conn, error) {
+	return fakeContextConn{}, nil
+}
+
+type fakeContextConn struct {
+	fakeConn
+}
+
+func (fakeContextConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (fakeContextConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (fakeContextConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query == "SELEC" {
+		return nil, errFakeQuery
+	}
+	return &fakeRows{}, nil
+}
+
+func (fakeContextConn) Ping(context.Context) error {
+	return nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+// fakeRows holds a single row with a single column.
+type fakeRows struct {
+	done bool
+}
+
+func (*fakeRows) Columns() []string {
+	return []string{"name"}
+}
+
+func (*fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = "gopher"
+	return nil
+}
+
+// customArg is an argument type only the drivers with a checker or a
+// converter accept.
+type customArg struct {
+	value string
+}
+
+// checkerDriver opens connections accepting `customArg` arguments.
+type checkerDriver struct{}
+
+func (checkerDriver) Open(string) (driver.Conn, error) {
+	return checkerConn{}, nil
+}
+
+type checkerConn struct {
+	fakeConn
+}
+
+func (checkerConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if arg, ok := nv.Value.(customArg); ok {
+		nv.Value = arg.value
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// converterDriver opens connections whose statements convert `customArg`
+// arguments.
+type converterDriver struct{}
+
+func (converterDriver) Open(string) (driver.Conn, error) {
+	return converterConn{}, nil
+}
+
+type converterConn struct {
+	fakeConn
+}
+
+func (converterConn) Prepare(query string) (driver.Stmt, error) {
+	return converterStmt{fakeStmt{query: query}}, nil
+}
+
+type converterStmt struct {
+	fakeStmt
+}
+
+func (converterStmt) ColumnConverter(int) driver.ValueConverter {
+	return customConverter{}
+}
+
+type customConverter struct{}
+
+func (customConverter) ConvertValue(v any) (driver.Value, error) {
+	if arg, ok := v.(customArg); ok {
+		return arg.value, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// skipDriver opens connections asking `database/sql` to fall back to prepared
+// statements when a query has arguments.
+type skipDriver struct{}
+
+func (skipDriver) Open(string) (driver.Conn, error) {
+	return skipConn{}, nil
+}
+
+type skipConn struct {
+	fakeConn
+}
+
+func (skipConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	return &fakeRows{}, nil
+}
+
+// closerDriver opens connectors recording whether they were closed.
+type closerDriver struct {
+	fakeDriver
+
+	connectors []*closerConnector
+}
+
+func (d *closerDriver) OpenConnector(string) (driver.Connector, error) {
+	c := &closerConnector{driver: d}
+	d.connectors = append(d.connectors, c)
+	return c, nil
+}
+
+type closerConnector struct {
+	driver driver.Driver
+	closed bool
+}
+
+func (c *closerConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+func (c *closerConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (c *closerConnector) Close() error {
+	c.closed = true
+	return nil
+}
+
+// openFakeDB registers the driver once under a name specific to the test and
+// opens it with `trace.OpenDB`.
+func openFakeDB(t *testing.T, d driver.Driver, opts ...trace.SQLOption) *sql.DB {
+	t.Helper()
+
+	name := "fake-" + t.Name()
+	if !slices.Contains(sql.Drivers(), name) {
+		sql.Register(name, d)
+	}
+
+	db, err := trace.OpenDB(name, "", opts...)
+	if err != nil {
+		t.Fatalf("OpenDB() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+// endedSpan returns the ended span with the name, failing the test if there is
+// none.
+func endedSpan(t *testing.T, sr *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for _, span := range sr.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("no %q span", name)
+	return nil
+}
+
+func TestOpenDBQuery(t *testing.T) {
+	sr, cleanup := setupTestTracer()
+	defer cleanup()
+
+	db := openFakeDB(t, fakeContextDriver{}, trace.WithDBSystem("postgresql"))
+
+	ctx, parent := trace.NewSpan(context.Background(), "parent", nil)
+	var name string
+	err := db.QueryRowContext(ctx, "SELECT name FROM users WHERE id = 42 AND status = 'active'").Scan(&name)
+	parent.End()
+	if err != nil {
+		t.Fatalf("QueryRowContext() error = %v", err)
+	}
+
+	span := endedSpan(t, sr, "db.query")
+	if span.SpanKind() != oteltrace.SpanKindClient {
+		t.Errorf("span kind = %v, want %v", span.SpanKind(), oteltrace.SpanKindClient)
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("db.query span is not a child of the current span")
+	}
+	if v, _ := spanAttribute(span, "db.system"); v.AsString() != "postgresql" {
+		t.Errorf("db.system = %q, want %q", v.AsString(), "postgresql")
+	}
+	want := "SELECT name FROM users WHERE id = ? AND status = ?"
+	if v, _ := spanAttribute(span, "db.statement"); v.AsString() != want {
+		t.Errorf("db.statement = %q, want %q", v.AsString(), want)
+	}
+
+	endedSpan(t, sr, "db.connect")
+}
+
+func TestOpenDBQueryError(t *testing.T) {
+	sr, cleanup := setupTestTracer()
+	defer cleanup()
+
+	db := openFakeDB(t, fakeContextDriver{})
+
+	_, err := db.QueryContext(context.Background(), "SELEC")
+	if !errors.Is(err, errFakeQuery) {
+		t.Fatalf("QueryContext() error = %v, want %v", err, errFakeQuery)
+	}
+
+	span := endedSpan(t, sr, "db.query")
+	if span.Status().Code != codes.Error {
+		t.Errorf("status = %v, want %v", span.Status().Code, codes.Error)
+	}
+	if v, _ := spanAttribute(span, "db.system"); v.AsString() != "other_sql" {
+		t.Errorf("db.system = %q, want %q", v.AsString(), "other_sql")
+	}
+}
+
+func TestOpenDBRawStatements(t *testing.T) {
+	sr, cleanup := setupTestTracer()
+	defer cleanup()
+
+	db := openFakeDB(t, fakeContextDriver{}, trace.WithRawDBStatements())
+
+	query := "UPDATE users SET name = 'gopher' WHERE id = 42"
+	if _, err := db.ExecContext(context.Background(), query); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	span := endedSpan(t, sr, "db.exec")
+	if v, _ := spanAttribute(span, "db.statement"); v.AsString() != query {
+		t.Errorf("db.statement = %q, want %q", v.AsString(), query)
+	}
+}
+
+func TestOpenDBPreparedStatementFallback(t *testing.T) {
+	sr, cleanup := setupTestTracer()
+	defer cleanup()
+
+	db := openFakeDB(t, fakeDriver{})
+
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM users WHERE id = 42"); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	want := "DELETE FROM users WHERE id = ?"
+	for _, name := range []string{"db.prepare", "db.exec"} {
+		span := endedSpan(t, sr, name)
+		if v, _ := spanAttribute(span, "db.statement"); v.AsString() != want {
+			t.Errorf("%s db.statement = %q, want %q", name, v.AsString(), want)
+		}
+	}
+	for _, span := range sr.Ended() {
+		if span.Status().Code == codes.Error {
+			t.Errorf("%s status = %v, want no error", span.Name(), span.Status().Code)
+		}
+	}
+}
+
+func TestOpenDBTransaction(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver driver.Driver
+		commit bool
+		want   string
+	}{
+		{name: "commit", driver: fakeContextDriver{}, commit: true, want: "db.commit"},
+		{name: "rollback", driver: fakeContextDriver{}, want: "db.rollback"},
+		{name: "without BeginTx", driver: fakeDriver{}, commit: true, want: "db.commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, cleanup := setupTestTracer()
+			defer cleanup()
+
+			db := openFakeDB(t, tt.driver)
+
+			ctx, parent := trace.NewSpan(context.Background(), "parent", nil)
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatalf("BeginTx() error = %v", err)
+			}
+			if tt.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+			parent.End()
+			if err != nil {
+				t.Fatalf("ending transaction: %v", err)
+			}
+
+			for _, name := range []string{"db.begin", tt.want} {
+				if span := endedSpan(t, sr, name); span.Parent().SpanID() != parent.SpanContext().SpanID() {
+					t.Errorf("%s span is not a child of the current span", name)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenDBUnsupportedTxOptions(t *testing.T) {
+	_, cleanup := setupTestTracer()
+	defer cleanup()
+
+	db := openFakeDB(t, fakeDriver{})
+
+	_, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if !errors.Is(err, trace.ErrUnsupportedTxOptions) {
+		t.Errorf("BeginTx() error = %v, want %v", err, trace.ErrUnsupportedTxOptions)
+	}
+}
+
+func TestOpenDBPing(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []trace.SQLOption
+		want bool
+	}{
+		{name: "default", want: true},
+		{name: "skipped", opts: []trace.SQLOption{trace.WithSkipDBPing()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, cleanup := setupTestTracer()
+			defer cleanup()
+
+			db := openFakeDB(t, fakeContextDriver{}, tt.opts...)
+			if err := db.PingContext(context.Background()); err != nil {
+				t.Fatalf("PingContext() error = %v", err)
+			}
+
+			got := slices.ContainsFunc(sr.Ended(), func(span sdktrace.ReadOnlySpan) bool {
+				return span.Name() == "db.ping"
+			})
+			if got != tt.want {
+				t.Errorf("db.ping span recorded = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenDBNamedValueChecker(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver driver.Driver
+	}{
+		{name: "connection checker", driver: checkerDriver{}},
+		{name: "column converter", driver: converterDriver{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cleanup := setupTestTracer()
+			defer cleanup()
+
+			db := openFakeDB(t, tt.driver)
+
+			stmt, err := db.PrepareContext(context.Background(), "UPDATE users SET name = ?")
+			if err != nil {
+				t.Fatalf("PrepareContext() error = %v", err)
+			}
+			defer stmt.Close()
+
+			if _, err := stmt.ExecContext(context.Background(), customArg{value: "gopher"}); err != nil {
+				t.Errorf("ExecContext() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenDBClose(t *testing.T) {
+	_, cleanup := setupTestTracer()
+	defer cleanup()
+
+	d := &closerDriver{}
+	db := openFakeDB(t, d)
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Fatalf("PingContext() error = %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(d.connectors) == 0 {
+		t.Fatal("no connector opened")
+	}
+	for i, c := range d.connectors {
+		if !c.closed {
+			t.Errorf("connector %d is not closed", i)
+		}
+	}
+}
+
+func TestOpenDBSkippedQuery(t *testing.T) {
+	sr, cleanup := setupTestTracer()
+	defer cleanup()
+
+	db := openFakeDB(t, skipDriver{})
+
+	var name string
+	if err := db.QueryRowContext(context.Background(), "SELECT name FROM users WHERE id = ?", 42).Scan(&name); err != nil {
+		t.Fatalf("QueryRowContext() error = %v", err)
+	}
+
+	var names []string
+	for _, span := range sr.Ended() {
+		names = append(names, span.Name())
+	}
+	if !slices.Equal(names, []string{"db.connect", "db.prepare", "db.query"}) {
+		t.Errorf("spans = %v, want [db.connect db.prepare db.query]", names)
+	}
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		mysql bool
+		want  string
+	}{
+		{
+			query: "SELECT name FROM users WHERE id = 42",
+			want:  "SELECT name FROM users WHERE id = ?",
+		},
+		{
+			query: "SELECT name FROM users WHERE name = 'O''Brien' AND note = E'a\\'b'",
+			want:  "SELECT name FROM users WHERE name = ? AND note = E?",
+		},
+		{
+			query: "SELECT id FROM files WHERE path = 'C:\\' AND token = 'hunter2secret'",
+			want:  "SELECT id FROM files WHERE path = ? AND token = ?",
+		},
+		{
+			query: "SELECT id FROM files WHERE path = 'C:\\\\' AND token = 'hunter2secret'",
+			mysql: true,
+			want:  "SELECT id FROM files WHERE path = ? AND token = ?",
+		},
+		{
+			query: `SELECT name FROM users WHERE name = "O\"Brien" AND note = 'a\'b'`,
+			mysql: true,
+			want:  "SELECT name FROM users WHERE name = ? AND note = ?",
+		},
+		{
+			query: "SELECT $$secret$$, $tag$it's $$ secret$tag$ FROM t WHERE a$b = $1",
+			want:  "SELECT ?, ? FROM t WHERE a$b = $1",
+		},
+		{
+			query: "SELECT price FROM items WHERE price > -1.5e-3 OR flags = 0x1F",
+			want:  "SELECT price FROM items WHERE price > -? OR flags = ?",
+		},
+		{
+			query: `SELECT "col1", t2.col3 FROM table2 t2 WHERE id = $1 AND code = @p2`,
+			want:  `SELECT "col1", t2.col3 FROM table2 t2 WHERE id = $1 AND code = @p2`,
+		},
+		{
+			query: "SELECT name FROM users -- id = 42\nWHERE id = 7 /* 'x' */",
+			want:  "SELECT name FROM users -- id = 42\nWHERE id = ? /* 'x' */",
+		},
+		{
+			query: "SELECT name FROM users WHERE name = 'unterminated",
+			want:  "SELECT name FROM users WHERE name = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		sanitize := trace.SanitizeSQL
+		if tt.mysql {
+			sanitize = trace.SanitizeMySQL
+		}
+		if got := sanitize(tt.query); got != tt.want {
+			t.Errorf("sanitize(%q) = %q, want %q (mysql: %v)", tt.query, got, tt.want, tt.mysql)
+		}
+	}
+}
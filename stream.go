@@ -0,0 +1,187 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// MessageDirection tells whether a message of a `Stream` was sent or
+// received.
+type MessageDirection int
+
+const (
+	// MessageSent is a message sent to the peer.
+	MessageSent MessageDirection = iota + 1
+	// MessageReceived is a message received from the peer.
+	MessageReceived
+)
+
+func (d MessageDirection) String() string {
+	switch d {
+	case MessageSent:
+		return "SENT"
+	case MessageReceived:
+		return "RECEIVED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// StreamOption configures a `Stream`.
+type StreamOption func(*streamConfig)
+
+type streamConfig struct {
+	spans      bool
+	sampleRate float64
+	attributes []attribute.KeyValue
+}
+
+// WithMessageSpans records each message as a child span of the connection
+// span rather than as an event.
+func WithMessageSpans() StreamOption {
+	return func(c *streamConfig) {
+		c.spans = true
+	}
+}
+
+// WithMessageSampleRate configures the fraction of the messages which are
+// recorded, between 0 and 1. All of them are counted nonetheless.
+func WithMessageSampleRate(rate float64) StreamOption {
+	return func(c *streamConfig) {
+		c.sampleRate = rate
+	}
+}
+
+// WithStreamAttributes adds attributes to the connection span.
+func WithStreamAttributes(attrs ...attribute.KeyValue) StreamOption {
+	return func(c *streamConfig) {
+		c.attributes = append(c.attributes, attrs...)
+	}
+}
+
+// Stream traces a long-lived connection, such as a WebSocket connection or a
+// Server-Sent Events stream, as a connection span along with an event or a
+// child span for each message. It is safe for concurrent use.
+type Stream struct {
+	ctx  context.Context
+	span trace.Span
+	name string
+	cfg  streamConfig
+
+	sent          atomic.Int64
+	received      atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+// StartStream starts the connection span of a stream as a child of the current
+// span of `ctx`, such as the span of the request which was upgraded. The
+// returned context holds the connection span. `Stream.End` must be called once
+// the connection is closed.
+func StartStream(ctx context.Context, name string, opts ...StreamOption) (context.Context, *Stream) {
+	cfg := streamConfig{sampleRate: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, span := NewSpan(ctx, name, spanAttributes(cfg.attributes))
+
+	return ctx, &Stream{
+		ctx:  ctx,
+		span: span,
+		name: name,
+		cfg:  cfg,
+	}
+}
+
+// spanAttributes is a `SpanCustomiser` setting attributes at span start.
+type spanAttributes []attribute.KeyValue
+
+func (a spanAttributes) Customise() []trace.SpanStartOption {
+	return []trace.SpanStartOption{trace.WithAttributes(a...)}
+}
+
+// Context returns the context holding the connection span.
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// Message records a message of `size` bytes. With `WithMessageSpans`, the
+// message is recorded as a child span of the connection span, which is
+// returned along with its context and must be ended by the caller once the
+// message was processed. Otherwise, or when the message is not sampled, the
+// returned span is a no-op and the context is the stream's one.
+func (s *Stream) Message(dir MessageDirection, size int) (context.Context, trace.Span) {
+	var id int64
+	if dir == MessageSent {
+		id = s.sent.Add(1)
+		s.bytesSent.Add(int64(size))
+	} else {
+		id = s.received.Add(1)
+		s.bytesReceived.Add(int64(size))
+	}
+
+	if !s.span.IsRecording() || !s.sampled() {
+		return s.ctx, noop.Span{}
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("message.type", dir.String()),
+		attribute.Int64("message.id", id),
+		attribute.Int("message.uncompressed_size", size),
+	}
+
+	if !s.cfg.spans {
+		s.span.AddEvent("message", trace.WithAttributes(attrs...))
+		return s.ctx, noop.Span{}
+	}
+
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return NewSpan(s.ctx, s.name+" "+messageOperation(dir), spanAttributes(attrs))
+}
+
+// End records the number of messages and bytes sent and received, records the
+// error which closed the connection if any with `RecordError`, and ends the
+// connection span. `io.EOF` and `net.ErrClosed`, returned when the peer or the
+// server closed the connection, do not flag the span as "failed".
+func (s *Stream) End(err error) {
+	s.span.SetAttributes(
+		attribute.Int64("stream.messages.sent", s.sent.Load()),
+		attribute.Int64("stream.messages.received", s.received.Load()),
+		attribute.Int64("stream.bytes.sent", s.bytesSent.Load()),
+		attribute.Int64("stream.bytes.received", s.bytesReceived.Load()),
+	)
+	RecordError(s.span, err, WithErrorClassifier(streamErrorClassifier))
+	s.span.End()
+}
+
+func (s *Stream) sampled() bool {
+	if s.cfg.sampleRate >= 1 {
+		return true
+	}
+	return s.cfg.sampleRate > 0 && rand.Float64() < s.cfg.sampleRate //nolint:gosec // Sampling needs no secure source.
+}
+
+// streamErrorClassifier treats the normal closures of a connection as
+// expected, and defers to the configured classifier otherwise.
+func streamErrorClassifier(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return false
+	}
+	return currentErrorClassifier()(err)
+}
+
+func messageOperation(dir MessageDirection) string {
+	if dir == MessageSent {
+		return "send"
+	}
+	return "receive"
+}
@@ -0,0 +1,128 @@
+package trace_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestStream(t *testing.T) {
+	t.Run("records messages as events", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		_, stream := trace.StartStream(context.Background(), "websocket /chat")
+		_, span := stream.Message(trace.MessageReceived, 12)
+		span.End()
+		_, span = stream.Message(trace.MessageSent, 30)
+		span.End()
+		stream.End(nil)
+
+		spans := sr.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		events := spans[0].Events()
+		if len(events) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(events))
+		}
+		if v, _ := eventAttribute(events[0], "message.type"); v != "RECEIVED" {
+			t.Errorf("expected message.type=RECEIVED, got %q", v)
+		}
+		if v, _ := eventAttribute(events[1], "message.type"); v != "SENT" {
+			t.Errorf("expected message.type=SENT, got %q", v)
+		}
+
+		for key, want := range map[attribute.Key]int64{
+			"stream.messages.sent":     1,
+			"stream.messages.received": 1,
+			"stream.bytes.sent":        30,
+			"stream.bytes.received":    12,
+		} {
+			if v, ok := spanAttribute(spans[0], key); !ok || v.AsInt64() != want {
+				t.Errorf("expected %s=%d, got %v", key, want, v.Emit())
+			}
+		}
+	})
+
+	t.Run("records messages as child spans", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		_, stream := trace.StartStream(context.Background(), "websocket /chat", trace.WithMessageSpans())
+		_, span := stream.Message(trace.MessageReceived, 12)
+		span.End()
+		stream.End(nil)
+
+		spans := sr.Ended()
+		if len(spans) != 2 {
+			t.Fatalf("expected 2 spans, got %d", len(spans))
+		}
+		if spans[0].Name() != "websocket /chat receive" {
+			t.Errorf("expected span name %q, got %q", "websocket /chat receive", spans[0].Name())
+		}
+		if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+			t.Error("expected the message span to be a child of the connection span")
+		}
+	})
+
+	t.Run("samples messages", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		_, stream := trace.StartStream(context.Background(), "websocket /chat", trace.WithMessageSampleRate(0))
+		for range 10 {
+			_, span := stream.Message(trace.MessageSent, 1)
+			span.End()
+		}
+		stream.End(nil)
+
+		spans := sr.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+		if len(spans[0].Events()) != 0 {
+			t.Errorf("expected no events, got %d", len(spans[0].Events()))
+		}
+		if v, _ := spanAttribute(spans[0], "stream.messages.sent"); v.AsInt64() != 10 {
+			t.Errorf("expected stream.messages.sent=10, got %v", v.Emit())
+		}
+	})
+
+	t.Run("records the closing error", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		_, stream := trace.StartStream(context.Background(), "websocket /chat")
+		stream.End(errors.New("connection reset"))
+
+		spans := sr.Ended()
+		if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+			t.Errorf("expected 1 failed span, got %v", spans)
+		}
+	})
+
+	t.Run("does not fail on normal closure", func(t *testing.T) {
+		for _, err := range []error{io.EOF, fmt.Errorf("reading: %w", net.ErrClosed)} {
+			sr, cleanup := setupTestTracer()
+
+			_, stream := trace.StartStream(context.Background(), "websocket /chat")
+			stream.End(err)
+
+			spans := sr.Ended()
+			if len(spans) != 1 || spans[0].Status().Code == codes.Error {
+				t.Errorf("%v: expected 1 span not failed, got %v", err, spans)
+			}
+			cleanup()
+		}
+	})
+}
@@ -5,8 +5,10 @@ import (
 	"os"
 
 	"github.com/sethvargo/go-envconfig"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 
 	"go.pixelfactory.io/pkg/observability/trace/provider"
@@ -14,6 +16,7 @@ import (
 
 type Provider struct {
 	config       Config
+	ctx          context.Context
 	ShutdownFunc provider.ShutdownFunc
 }
 
@@ -95,13 +98,25 @@ func setupTracing(c Config) (provider.ShutdownFunc, error) {
 	if !c.TraceEnabled {
 		return func() error { return nil }, nil
 	}
+
+	var processors []sdktrace.SpanProcessor
+	if c.SpanLeakDetection {
+		processors = append(processors, NewLeakDetector(WithLeakMaxAge(c.SpanLeakMaxAge)))
+	}
+	if len(c.BaggageSpanAttributes) > 0 {
+		processors = append(processors, NewBaggageSpanProcessor(c.BaggageSpanAttributes...))
+	}
+
 	return provider.InitProvider(provider.Config{
-		Endpoint:      c.SpanExporterEndpoint,
-		Insecure:      c.SpanExporterEndpointInsecure,
-		Headers:       c.Headers,
-		Resource:      c.Resource,
-		Propagators:   c.Propagators,
-		TraceExporter: c.TraceExporter,
+		Endpoint:           c.SpanExporterEndpoint,
+		Insecure:           c.SpanExporterEndpointInsecure,
+		Headers:            c.Headers,
+		Resource:           c.Resource,
+		Propagators:        c.Propagators,
+		ExtractPropagators: c.ExtractPropagators,
+		InjectPropagators:  c.InjectPropagators,
+		TraceExporter:      c.TraceExporter,
+		SpanProcessors:     processors,
 	})
 }
 
@@ -121,14 +136,57 @@ func NewProvider(opts ...Option) (*Provider, error) {
 		return nil, err
 	}
 
+	// Package defaults are only replaced once tracing is set up, so that a
+	// failed or disabled provider leaves them untouched.
+	if c.TraceEnabled {
+		if len(c.TracerName) > 0 {
+			SetDefaultTracer(Tracer(c.TracerName, "", ""))
+		}
+		if len(c.HTTPExcludedPaths) > 0 {
+			SetDefaultHTTPFilter(httpPathFilter(c.HTTPExcludedPaths, c.HTTPExcludedSampleRate))
+		}
+	}
+
+	ctx := context.Background()
+	if c.EnvironmentContext {
+		ctx = ContextFromEnvironment(ctx)
+	}
+
 	p := &Provider{
 		config:       c,
+		ctx:          ctx,
 		ShutdownFunc: shutdown,
 	}
 
 	return p, nil
 }
 
+// Context returns the root context of the process. When enabled with
+// `WithEnvironmentContext`, it holds the trace context and baggage propagated
+// by the parent process, see `InjectCommand`.
+func (p Provider) Context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
 func (p Provider) Shutdown() error {
 	return p.ShutdownFunc()
 }
+
+// ForceFlush exports the spans which were ended but not exported yet. Call it
+// before short-lived processes exit, or use `Job` which flushes after each run.
+// It does nothing when tracing is disabled.
+func (p Provider) ForceFlush(ctx context.Context) error {
+	return forceFlush(ctx)
+}
+
+// forceFlush flushes the global tracer provider, if it supports it.
+func forceFlush(ctx context.Context) error {
+	flusher, ok := otel.GetTracerProvider().(interface{ ForceFlush(context.Context) error })
+	if !ok {
+		return nil
+	}
+	return flusher.ForceFlush(ctx)
+}
@@ -1,10 +1,15 @@
 package trace_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"go.pixelfactory.io/pkg/observability/trace"
 )
@@ -157,6 +162,51 @@ func TestProviderWithCustomExporter(t *testing.T) {
 	})
 }
 
+func TestProviderWithSpanLeakDetection(t *testing.T) {
+	t.Parallel()
+
+	provider, err := trace.NewProvider(
+		trace.WithTraceEnabled(true),
+		trace.WithServiceName("leak-detection-service"),
+		trace.WithTraceExporter(createExporter(t)),
+		trace.WithPropagators([]string{"b3"}),
+		trace.WithSpanLeakDetection(true),
+		trace.WithSpanLeakMaxAge(time.Minute),
+	)
+
+	testProviderSuccess(t, provider, err)
+}
+
+func TestProviderContext(t *testing.T) {
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+	t.Run("ignores environment by default", func(t *testing.T) {
+		provider, err := trace.NewProvider(trace.WithTraceEnabled(false))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		if trace.SpanFromContext(provider.Context()).SpanContext().IsValid() {
+			t.Error("expected no span context")
+		}
+	})
+
+	t.Run("extracts environment when enabled", func(t *testing.T) {
+		provider, err := trace.NewProvider(
+			trace.WithTraceEnabled(false),
+			trace.WithEnvironmentContext(true),
+		)
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		sc := trace.SpanFromContext(provider.Context()).SpanContext()
+		if sc.TraceID().String() != "0af7651916cd43dd8448eb211c80319c" {
+			t.Errorf("expected trace ID %q, got %q", "0af7651916cd43dd8448eb211c80319c", sc.TraceID())
+		}
+	})
+}
+
 func TestProviderHeadersMerge(t *testing.T) {
 	t.Parallel()
 
@@ -171,3 +221,73 @@ func TestProviderHeadersMerge(t *testing.T) {
 
 	testProviderSuccess(t, provider, err)
 }
+
+func TestProviderForceFlush(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	provider, err := trace.NewProvider(
+		trace.WithTraceEnabled(true),
+		trace.WithServiceName("force-flush-service"),
+		trace.WithTraceExporter(exp),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Shutdown() }()
+
+	_, span := trace.NewSpan(context.Background(), "flushed", nil)
+	span.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+	if spans := exp.GetSpans(); len(spans) != 1 || spans[0].Name != "flushed" {
+		t.Errorf("exported spans = %v, want the flushed span", spans)
+	}
+}
+
+func TestNewProviderFailureKeepsDefaults(t *testing.T) {
+	t.Setenv("OTEL_TRACE_HTTP_EXCLUDED_PATHS", "/metrics")
+
+	tests := []struct {
+		name string
+		opts []trace.Option
+	}{
+		{
+			name: "setup failure",
+			opts: []trace.Option{
+				trace.WithTraceEnabled(true),
+				trace.WithTraceExporter(tracetest.NewInMemoryExporter()),
+				trace.WithPropagators([]string{"unknown"}),
+			},
+		},
+		{
+			name: "tracing disabled",
+			opts: []trace.Option{trace.WithTraceEnabled(false)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				trace.SetDefaultTracer(nil)
+				trace.SetDefaultHTTPFilter(nil)
+			})
+
+			provider, _ := trace.NewProvider(append(tt.opts, trace.WithTracerName("example.com/orders"))...)
+			if provider != nil {
+				_ = provider.Shutdown()
+			}
+
+			if name := trace.DefaultTracer().Name(); name != trace.DefaultTracerName {
+				t.Errorf("default tracer = %q, want %q", name, trace.DefaultTracerName)
+			}
+
+			sr := setupHTTPTestTracer(t)
+			handler := trace.HTTPHandler(http.NotFoundHandler(), "operation")
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			if len(sr.Ended()) != 1 {
+				t.Error("default HTTP filter was set")
+			}
+		})
+	}
+}
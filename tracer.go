@@ -0,0 +1,115 @@
+package trace
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+// DefaultTracerName is the instrumentation scope name used by the package-level
+// helpers until another one is configured with `SetDefaultTracer` or the
+// `WithTracerName` option.
+const DefaultTracerName = "go.pixelfactory.io/pkg/observability/trace"
+
+//nolint:gochecknoglobals // Scope shared by the package-level span helpers.
+var defaultTracer atomic.Pointer[NamedTracer]
+
+// NamedTracer creates spans under a named instrumentation scope so that the
+// library or module which produced a span can be told apart from the others.
+// The underlying tracer is resolved from the global tracer provider on every
+// call, hence a handle may be created before `NewProvider` is called.
+type NamedTracer struct {
+	name string
+	opts []trace.TracerOption
+}
+
+// Tracer returns a new `NamedTracer` handle. The `version` and `schemaURL`
+// arguments are optional and ignored when empty.
+func Tracer(name, version, schemaURL string) *NamedTracer {
+	var opts []trace.TracerOption
+	if len(version) > 0 {
+		opts = append(opts, trace.WithInstrumentationVersion(version))
+	}
+	if len(schemaURL) > 0 {
+		opts = append(opts, trace.WithSchemaURL(schemaURL))
+	}
+
+	return &NamedTracer{
+		name: name,
+		opts: opts,
+	}
+}
+
+// DefaultTracer returns the handle used by the package-level helpers such as
+// `NewSpan`.
+func DefaultTracer() *NamedTracer {
+	if t := defaultTracer.Load(); t != nil {
+		return t
+	}
+	return Tracer(DefaultTracerName, version, "")
+}
+
+// SetDefaultTracer replaces the handle used by the package-level helpers. A nil
+// handle restores the library default.
+func SetDefaultTracer(t *NamedTracer) {
+	defaultTracer.Store(t)
+}
+
+// Name returns the instrumentation scope name of the handle.
+func (t *NamedTracer) Name() string {
+	return t.name
+}
+
+// Tracer returns the OpenTelemetry tracer of the handle from the global tracer
+// provider.
+func (t *NamedTracer) Tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(t.name, t.opts...)
+}
+
+// NewSpan returns a new span from the handle's tracer. It behaves like the
+// package-level `NewSpan` function.
+func (t *NamedTracer) NewSpan(ctx context.Context, name string, cus SpanCustomiser) (context.Context, trace.Span) {
+	if cus == nil {
+		//nolint:spancheck // Caller is responsible for calling span.End()
+		return t.Tracer().Start(ctx, name)
+	}
+
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return t.Tracer().Start(ctx, name, cus.Customise()...)
+}
+
+// SpanFromContext returns the current span from a context. It behaves like the
+// package-level `SpanFromContext` function.
+func (t *NamedTracer) SpanFromContext(ctx context.Context) trace.Span {
+	return trace.SpanFromContext(ctx)
+}
+
+// globalTracerProvider resolves the global tracer provider whenever a span is
+// started, hence instrumentation created before `NewProvider` is called, or
+// configured with a tracer at construction, still uses the provider's one.
+type globalTracerProvider struct {
+	embedded.TracerProvider
+}
+
+func (globalTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return globalTracer{name: name, opts: opts}
+}
+
+type globalTracer struct {
+	embedded.Tracer
+
+	name string
+	opts []trace.TracerOption
+}
+
+func (t globalTracer) Start(
+	ctx context.Context,
+	spanName string,
+	opts ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	//nolint:spancheck // Caller is responsible for calling span.End()
+	return otel.GetTracerProvider().Tracer(t.name, t.opts...).Start(ctx, spanName, opts...)
+}
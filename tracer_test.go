@@ -0,0 +1,76 @@
+package trace_test
+
+import (
+	"context"
+	"testing"
+
+	"go.pixelfactory.io/pkg/observability/trace"
+)
+
+func TestTracer(t *testing.T) {
+	sr, cleanup := setupTestTracer()
+	defer cleanup()
+
+	tracer := trace.Tracer("example.com/orders", "1.2.3", "https://opentelemetry.io/schemas/1.24.0")
+
+	ctx, span := tracer.NewSpan(context.Background(), "named-span", nil)
+	if tracer.SpanFromContext(ctx).SpanContext().SpanID() != span.SpanContext().SpanID() {
+		t.Error("retrieved span does not match original span")
+	}
+	span.End()
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	scope := spans[0].InstrumentationScope()
+	if scope.Name != "example.com/orders" {
+		t.Errorf("expected scope name %q, got %q", "example.com/orders", scope.Name)
+	}
+	if scope.Version != "1.2.3" {
+		t.Errorf("expected scope version %q, got %q", "1.2.3", scope.Version)
+	}
+	if scope.SchemaURL != "https://opentelemetry.io/schemas/1.24.0" {
+		t.Errorf("expected scope schema URL %q, got %q", "https://opentelemetry.io/schemas/1.24.0", scope.SchemaURL)
+	}
+}
+
+func TestDefaultTracer(t *testing.T) {
+	t.Run("uses library scope by default", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		_, span := trace.NewSpan(context.Background(), "default-span", nil)
+		span.End()
+
+		spans := sr.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		if name := spans[0].InstrumentationScope().Name; name != trace.DefaultTracerName {
+			t.Errorf("expected scope name %q, got %q", trace.DefaultTracerName, name)
+		}
+	})
+
+	t.Run("uses configured scope", func(t *testing.T) {
+		sr, cleanup := setupTestTracer()
+		defer cleanup()
+
+		trace.SetDefaultTracer(trace.Tracer("example.com/billing", "", ""))
+		defer trace.SetDefaultTracer(nil)
+
+		_, span := trace.NewSpan(context.Background(), "configured-span", nil)
+		span.End()
+
+		spans := sr.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		if name := spans[0].InstrumentationScope().Name; name != "example.com/billing" {
+			t.Errorf("expected scope name %q, got %q", "example.com/billing", name)
+		}
+	})
+}